@@ -4,6 +4,7 @@
 package errorly
 
 import (
+	"reflect"
 	"time"
 
 	idgenerator "github.com/TheRockettek/Errorly-Web/pkg/idgenerator"
@@ -143,7 +144,20 @@ func createSchema(db *pg.DB) (err error) {
 		&structs.Webhook{},
 		&structs.IssueEntry{},
 		&structs.Comment{},
+		&structs.CommentEdit{},
 		&structs.InviteCode{},
+		&structs.SavedSearch{},
+		&structs.AffectedUser{},
+		&structs.Release{},
+		&structs.Label{},
+		&structs.IssueLabel{},
+		&structs.Notification{},
+		&structs.IssueSubscription{},
+		&structs.Reaction{},
+		&structs.Activity{},
+		&structs.AuditLogEntry{},
+		&structs.WebhookDelivery{},
+		&structs.WebhookDeliveryAttempt{},
 	}
 
 	for _, model := range models {
@@ -160,6 +174,11 @@ func createSchema(db *pg.DB) (err error) {
 		if err != nil {
 			panic(err)
 		}
+
+		err = addMissingColumns(db, model)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	if refresh {
@@ -528,3 +547,57 @@ func createSchema(db *pg.DB) (err error) {
 
 	return nil
 }
+
+// addMissingColumns adds the columns of a model that are missing from its
+// table. CreateTable does not change tables that already exist so fields
+// added to a model after its table was created are added here. Existing
+// rows are given the default of the column, or the zero value if the field
+// is use_zero.
+func addMissingColumns(db *pg.DB, model interface{}) (err error) {
+	table := orm.GetTable(reflect.TypeOf(model).Elem())
+
+	for _, field := range table.DataFields {
+		sqlType := field.UserSQLType
+		if sqlType == "" {
+			sqlType = field.SQLType
+		}
+
+		query := "ALTER TABLE ? ADD COLUMN IF NOT EXISTS ? " + sqlType
+
+		switch {
+		case field.Default != "":
+			query += " DEFAULT " + string(field.Default)
+		case zeroColumnDefault(field) != "":
+			query += " DEFAULT " + zeroColumnDefault(field)
+		}
+
+		_, err = db.Exec(query, table.SQLName, field.Column)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// zeroColumnDefault returns the zero value of a use_zero field as SQL.
+// Returns an empty string if the field stores NULL for its zero value, is
+// a pointer where NULL means it is not set, or has no simple zero value.
+func zeroColumnDefault(field *orm.Field) string {
+	if field.NullZero() {
+		return ""
+	}
+
+	switch field.Field.Type.Kind() {
+	case reflect.Bool:
+		return "false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "0"
+	case reflect.String:
+		return "''"
+	}
+
+	return ""
+}
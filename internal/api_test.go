@@ -0,0 +1,41 @@
+package errorly
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+func TestZeroColumnDefault(t *testing.T) {
+	tests := []struct {
+		model  interface{}
+		column string
+		want   string
+	}{
+		{&structs.IssueEntry{}, "priority", "0"},
+		{&structs.IssueEntry{}, "reaction_count", "0"},
+		{&structs.IssueEntry{}, "starred", "false"},
+		{&structs.IssueEntry{}, "snooze_occurrences", ""},
+		{&structs.IssueEntry{}, "error", ""},
+		{&structs.Webhook{}, "events", "0"},
+		{&structs.Webhook{}, "cloud_events", "0"},
+		{&structs.Comment{}, "issue_marked", ""},
+	}
+
+	for _, test := range tests {
+		table := orm.GetTable(reflect.TypeOf(test.model).Elem())
+
+		field, ok := table.FieldsMap[test.column]
+		if !ok {
+			t.Errorf("%s has no column %s", table.TypeName, test.column)
+
+			continue
+		}
+
+		if got := zeroColumnDefault(field); got != test.want {
+			t.Errorf("zeroColumnDefault(%s.%s) = %q, want %q", table.TypeName, test.column, got, test.want)
+		}
+	}
+}
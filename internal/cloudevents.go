@@ -0,0 +1,89 @@
+package errorly
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+// cloudEventsSpecVersion is the version of the CloudEvents specification
+// events are sent with.
+const cloudEventsSpecVersion = "1.0"
+
+// cloudEventTypes contains the CloudEvents type of each WebhookEventType.
+var cloudEventTypes = map[structs.WebhookEventType]string{
+	structs.TestWebhook:         "dev.errorly.webhook.test",
+	structs.IssueCreate:         "dev.errorly.issue.created",
+	structs.IssueComment:        "dev.errorly.issue.comment.created",
+	structs.IssueStarred:        "dev.errorly.issue.starred",
+	structs.IssueAssigned:       "dev.errorly.issue.assigned",
+	structs.IssueLocked:         "dev.errorly.issue.locked",
+	structs.IssueMarkStatus:     "dev.errorly.issue.status_changed",
+	structs.IssueLabeled:        "dev.errorly.issue.labeled",
+	structs.IssueCommentEdited:  "dev.errorly.issue.comment.edited",
+	structs.IssueCommentDeleted: "dev.errorly.issue.comment.deleted",
+}
+
+// newCloudEvent returns the CloudEvent of a payload. The source is the url
+// of the project, the subject is the ID of the issue, if there is one, and
+// the time is when the delivery was created.
+func (er *Errorly) newCloudEvent(payload structs.WebhookMessage, deliveryID int64,
+	createdAt time.Time) structs.CloudEvent {
+	source := er.Configuration.URL
+	if payload.Project != nil {
+		source = fmt.Sprintf("%s/project/%d", er.Configuration.URL, payload.Project.ID)
+	}
+
+	event := structs.CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		Type:            cloudEventTypes[payload.Type],
+		Source:          source,
+		ID:              strconv.FormatInt(deliveryID, 10),
+		Time:            createdAt.UTC().Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            payload,
+	}
+
+	if payload.Issue != nil {
+		event.Subject = strconv.FormatInt(payload.Issue.ID, 10)
+	}
+
+	return event
+}
+
+// cloudEventRequest returns a request containing a payload as a CloudEvent
+// in the passed content mode.
+func (er *Errorly) cloudEventRequest(payload structs.WebhookMessage, deliveryID int64,
+	createdAt time.Time, mode structs.CloudEventsMode) (req *WebhookRequest, err error) {
+	event := er.newCloudEvent(payload, deliveryID, createdAt)
+
+	if mode == structs.CloudEventsStructured {
+		req, err = jsonRequest(event)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+
+		return req, nil
+	}
+
+	req, err = jsonRequest(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("ce-specversion", event.SpecVersion)
+	req.Header.Set("ce-type", event.Type)
+	req.Header.Set("ce-source", event.Source)
+	req.Header.Set("ce-id", event.ID)
+	req.Header.Set("ce-time", event.Time)
+
+	if event.Subject != "" {
+		req.Header.Set("ce-subject", event.Subject)
+	}
+
+	return req, nil
+}
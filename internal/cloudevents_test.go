@@ -0,0 +1,60 @@
+package errorly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+func TestCloudEventTime(t *testing.T) {
+	er := newTestErrorly(t)
+	payload := testWebhookMessage(structs.IssueCreate)
+
+	createdAt := time.Date(2021, 3, 30, 0, 0, 0, 0, time.UTC)
+	want := "2021-03-30T00:00:00Z"
+
+	structured := &structs.Webhook{
+		URL:         "https://errorly.example/webhook",
+		Type:        structs.RegularPayload,
+		CloudEvents: structs.CloudEventsStructured,
+	}
+
+	// Every attempt of a delivery has the time the delivery was created.
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := er.FormatWebhook(structured, payload, 123, createdAt)
+		if err != nil {
+			t.Fatalf("FormatWebhook() error = %v", err)
+		}
+
+		event := structs.CloudEvent{}
+
+		err = json.Unmarshal(req.Body, &event)
+		if err != nil {
+			t.Fatalf("Failed to decode event %s: %v", req.Body, err)
+		}
+
+		if event.Time != want {
+			t.Errorf("time = %s, want %s", event.Time, want)
+		}
+
+		if event.ID != "123" {
+			t.Errorf("id = %s, want 123", event.ID)
+		}
+	}
+
+	binary := &structs.Webhook{
+		URL:         "https://errorly.example/webhook",
+		Type:        structs.RegularPayload,
+		CloudEvents: structs.CloudEventsBinary,
+	}
+
+	req, err := er.FormatWebhook(binary, payload, 123, createdAt)
+	if err != nil {
+		t.Fatalf("FormatWebhook() error = %v", err)
+	}
+
+	if eventTime := req.Header.Get("ce-time"); eventTime != want {
+		t.Errorf("ce-time = %s, want %s", eventTime, want)
+	}
+}
@@ -13,9 +13,12 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	idgenerator "github.com/TheRockettek/Errorly-Web/pkg/idgenerator"
+	"github.com/TheRockettek/Errorly-Web/pkg/markdown"
+	"github.com/TheRockettek/Errorly-Web/pkg/signature"
 	"github.com/TheRockettek/Errorly-Web/structs"
 	sandwich "github.com/TheRockettek/Sandwich-Daemon/structs"
 	"github.com/go-pg/pg/v10"
@@ -59,6 +62,12 @@ type Configuration struct {
 	Postgres *pg.Options    `json:"postgres" yaml:"postgres"`
 	OAuth    *oauth2.Config `json:"oauth" yaml:"oauth"`
 
+	Webhooks struct {
+		Workers     int `json:"workers" yaml:"workers"`           // Number of workers sending webhooks
+		Timeout     int `json:"timeout" yaml:"timeout"`           // Seconds before a webhook request is cancelled
+		MaxAttempts int `json:"max_attempts" yaml:"max_attempts"` // Attempts before a delivery is marked as failed
+	} `json:"webhooks" yaml:"webhooks"`
+
 	Logging struct {
 		ConsoleLoggingEnabled bool `json:"console_logging" yaml:"console_logging"`
 		FileLoggingEnabled    bool `json:"file_logging" yaml:"file_logging"`
@@ -80,6 +89,9 @@ type Errorly struct {
 
 	client *http.Client
 
+	// Signals idle webhook workers that a delivery has been queued.
+	webhookWake chan struct{}
+
 	Configuration *Configuration `json:"configuration"`
 
 	Logger zerolog.Logger `json:"-"`
@@ -119,6 +131,8 @@ func NewErrorly(logger io.Writer, level zerolog.Level) (er *Errorly, err error)
 
 		client: http.DefaultClient,
 
+		webhookWake: make(chan struct{}, 1),
+
 		Logger: zerolog.New(logger).With().Timestamp().Logger(),
 	}
 
@@ -134,6 +148,24 @@ func NewErrorly(logger io.Writer, level zerolog.Level) (er *Errorly, err error)
 
 	er.Configuration = configuration
 
+	if er.Configuration.Webhooks.Workers <= 0 {
+		er.Configuration.Webhooks.Workers = defaultWebhookWorkers
+	}
+
+	if er.Configuration.Webhooks.Timeout <= 0 {
+		er.Configuration.Webhooks.Timeout = defaultWebhookTimeout
+	}
+
+	if er.Configuration.Webhooks.MaxAttempts <= 0 {
+		er.Configuration.Webhooks.MaxAttempts = defaultWebhookMaxAttempts
+	}
+
+	// Every webhook attempt is given its own timeout so a slow endpoint
+	// cannot hold up a worker.
+	er.client = &http.Client{
+		Timeout: time.Duration(er.Configuration.Webhooks.Timeout) * time.Second,
+	}
+
 	// Create logging writers.
 	var writers []io.Writer
 
@@ -306,6 +338,10 @@ func (er *Errorly) Open(removeStaleEntries bool) (err error) {
 
 	er.Logger.Debug().Msg("Created schema")
 
+	go er.RunTasks()
+
+	er.RunWebhookWorkers()
+
 	er.Logger.Debug().Msg("Creating endpoints")
 	er.Router = createEndpoints(er)
 	er.Logger.Debug().Msg("Created endpoints")
@@ -333,46 +369,52 @@ func (er *Errorly) generateSecret(body io.Reader, secret string) (string, error)
 	return hex.EncodeToString(mac.Sum(nil)), nil
 }
 
-// HandleProjectWebhook handles sending a webhook.
+// HandleProjectWebhook queues the payload for every active webhook of the
+// project that is subscribed to the event and whose filters match the
+// issue. The deliveries are sent by the webhook workers. A webhook that
+// fails to queue does not stop the payload being queued for the others.
 func (er *Errorly) HandleProjectWebhook(project *structs.Project, payload structs.WebhookMessage) (err error) {
 	er.Logger.Debug().Msg("received new webhook request, webhooks: " + strconv.Itoa(len(project.Webhooks)))
 
+	var (
+		failed   int
+		firstErr error
+	)
+
 	for _, webhook := range project.Webhooks {
 		er.Logger.Debug().Msg("found webhook " + strconv.Itoa(int(webhook.ID)) + " active? " + strconv.FormatBool(webhook.Active))
 
 		if webhook.Active {
-			ok, err := er.DoWebhook(webhook, payload)
+			matches, err := er.webhookMatches(webhook, payload)
 			if err != nil {
-				er.Logger.Warn().Err(err).Msg("Failed to execute webhook")
-			}
+				er.Logger.Warn().Err(err).Int64("webhook_id", webhook.ID).Msg("Failed to filter webhook")
 
-			if err != nil {
-				er.Logger.Error().Err(err).Msg("Failed to execute webhook")
+				continue
 			}
 
-			if !ok {
-				webhook.Failures++
-			} else {
-				webhook.Failures = 0
-			}
-
-			if webhook.Failures >= 5 {
-				webhook.Active = false
-			} else {
-				webhook.Active = true
+			if !matches {
+				continue
 			}
 
-			_, err = er.Postgres.Model(webhook).
-				WherePK().
-				Update()
+			_, err = er.EnqueueWebhook(webhook, payload)
 			if err != nil {
-				er.Logger.Error().Err(err).Msg("Failed to update webhook")
+				er.Logger.Error().Err(err).Int64("webhook_id", webhook.ID).Msg("Failed to queue webhook")
 
-				return xerrors.Errorf("Failed to update webhook: %w", err)
+				if firstErr == nil {
+					firstErr = err
+				}
+
+				failed++
+
+				continue
 			}
 		}
 	}
 
+	if failed > 0 {
+		return xerrors.Errorf("Failed to queue %d webhooks: %w", failed, firstErr)
+	}
+
 	return nil
 }
 
@@ -384,6 +426,16 @@ func cutString(s string, l int) string {
 	return s[0:l] + "..."
 }
 
+// labelNames returns a comma separated list of label names.
+func labelNames(labels []*structs.Label) string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
 // ConvertErrorlyToDiscordWebhook handles converting a default payload to a
 // suitable discord webhook payload.
 func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage) (bool, sandwich.WebhookMessage) {
@@ -398,7 +450,8 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 				{
 					Title:       fmt.Sprintf("[%s] Issue opened: %s", payload.Project.Settings.DisplayName, payload.Issue.Error),
 					URL:         fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
-					Description: cutString(payload.Issue.Description, 2000),
+					Color:       payload.Issue.Priority.Color(),
+					Description: cutString(markdown.ToDiscord(payload.Issue.Description), 2000),
 					Author: &sandwich.EmbedAuthor{
 						Name:    payload.Author.Name,
 						IconURL: payload.Author.Avatar,
@@ -412,7 +465,37 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 				{
 					Title:       fmt.Sprintf("[%s] New comment on issue: %s", payload.Project.Settings.DisplayName, payload.Issue.Error),
 					URL:         fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
-					Description: cutString(*payload.Comment.Content, 2000),
+					Color:       payload.Issue.Priority.Color(),
+					Description: cutString(markdown.ToDiscord(*payload.Comment.Content), 2000),
+					Author: &sandwich.EmbedAuthor{
+						Name:    payload.Author.Name,
+						IconURL: payload.Author.Avatar,
+					},
+				},
+			},
+		}
+	case structs.IssueCommentEdited:
+		return true, sandwich.WebhookMessage{
+			Embeds: []sandwich.Embed{
+				{
+					Title:       fmt.Sprintf("[%s] Comment edited on issue: %s", payload.Project.Settings.DisplayName, payload.Issue.Error),
+					URL:         fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+					Color:       payload.Issue.Priority.Color(),
+					Description: cutString(markdown.ToDiscord(*payload.Comment.Content), 2000),
+					Author: &sandwich.EmbedAuthor{
+						Name:    payload.Author.Name,
+						IconURL: payload.Author.Avatar,
+					},
+				},
+			},
+		}
+	case structs.IssueCommentDeleted:
+		return true, sandwich.WebhookMessage{
+			Embeds: []sandwich.Embed{
+				{
+					Title: fmt.Sprintf("[%s] Comment deleted on issue: %s", payload.Project.Settings.DisplayName, payload.Issue.Error),
+					URL:   fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+					Color: payload.Issue.Priority.Color(),
 					Author: &sandwich.EmbedAuthor{
 						Name:    payload.Author.Name,
 						IconURL: payload.Author.Avatar,
@@ -431,6 +514,7 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 				{
 					Title: fmt.Sprintf("[%s] New star added to %s", payload.Project.Settings.DisplayName, payload.Issue.Error),
 					URL:   fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+					Color: payload.Issue.Priority.Color(),
 					Author: &sandwich.EmbedAuthor{
 						Name:    payload.Author.Name,
 						IconURL: payload.Author.Avatar,
@@ -445,6 +529,7 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 					{
 						Title: fmt.Sprintf("[%s] Issue %s has been unassigned", payload.Project.Settings.DisplayName, payload.Issue.Error),
 						URL:   fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+						Color: payload.Issue.Priority.Color(),
 						Author: &sandwich.EmbedAuthor{
 							Name:    payload.Author.Name,
 							IconURL: payload.Author.Avatar,
@@ -459,6 +544,7 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 				{
 					Title: fmt.Sprintf("[%s] Issue %s assigned to %s", payload.Project.Settings.DisplayName, payload.Issue.Error, payload.Issue.Assignee.Name),
 					URL:   fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+					Color: payload.Issue.Priority.Color(),
 					Author: &sandwich.EmbedAuthor{
 						Name:    payload.Author.Name,
 						IconURL: payload.Author.Avatar,
@@ -480,6 +566,7 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 				{
 					Title: fmt.Sprintf("[%s] Issue %s has been %s", payload.Project.Settings.DisplayName, payload.Issue.Error, issueLockedString),
 					URL:   fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+					Color: payload.Issue.Priority.Color(),
 					Author: &sandwich.EmbedAuthor{
 						Name:    payload.Author.Name,
 						IconURL: payload.Author.Avatar,
@@ -493,6 +580,38 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 				{
 					Title: fmt.Sprintf("[%s] Issue %s has been marked %s", payload.Project.Settings.DisplayName, payload.Issue.Error, payload.Issue.Type.String()),
 					URL:   fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+					Color: payload.Issue.Priority.Color(),
+					Author: &sandwich.EmbedAuthor{
+						Name:    payload.Author.Name,
+						IconURL: payload.Author.Avatar,
+					},
+				},
+			},
+		}
+	case structs.IssueLabeled:
+		fields := make([]*sandwich.EmbedField, 0, 2)
+
+		if len(payload.AddedLabels) > 0 {
+			fields = append(fields, &sandwich.EmbedField{
+				Name:  "Added",
+				Value: labelNames(payload.AddedLabels),
+			})
+		}
+
+		if len(payload.RemovedLabels) > 0 {
+			fields = append(fields, &sandwich.EmbedField{
+				Name:  "Removed",
+				Value: labelNames(payload.RemovedLabels),
+			})
+		}
+
+		return true, sandwich.WebhookMessage{
+			Embeds: []sandwich.Embed{
+				{
+					Title:  fmt.Sprintf("[%s] Labels changed on issue %s", payload.Project.Settings.DisplayName, payload.Issue.Error),
+					URL:    fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+					Color:  payload.Issue.Priority.Color(),
+					Fields: fields,
 					Author: &sandwich.EmbedAuthor{
 						Name:    payload.Author.Name,
 						IconURL: payload.Author.Avatar,
@@ -505,64 +624,131 @@ func (er *Errorly) ConvertErrorlyToDiscordWebhook(payload structs.WebhookMessage
 	return false, sandwich.WebhookMessage{}
 }
 
-// DoWebhook handles a webhook message.
-func (er *Errorly) DoWebhook(webhook *structs.Webhook, payload structs.WebhookMessage) (ok bool, err error) {
-	var res []byte
+// DoTestWebhook sends a test message to a webhook. Alerts triggered in
+// pagerduty by the test are resolved straight after.
+func (er *Errorly) DoTestWebhook(webhook *structs.Webhook) (ok bool, err error) {
+	testPayload := structs.WebhookMessage{
+		Type: structs.TestWebhook,
+	}
+
+	ok, err = er.DoWebhook(webhook, testPayload, time.Now().UTC(), nil)
+	if !ok || err != nil || webhook.Type != structs.PagerDutyWebhook {
+		return ok, err
+	}
 
-	if webhook.Type == structs.RegularPayload {
-		res, err = json.Marshal(payload)
-		if err != nil {
-			return false, xerrors.Errorf("Failed to marshal payload: %w", err)
-		}
-	} else {
-		// convert payload to discord format
-		ok, msg := er.ConvertErrorlyToDiscordWebhook(payload)
-		if !ok {
-			return true, nil
-		}
+	return er.ResolvePagerDutyTestWebhook(webhook)
+}
 
-		res, err = json.Marshal(msg)
-		if err != nil {
-			return false, err
+// DoWebhook handles a webhook message of a delivery created at createdAt.
+// If attempt is not nil, the request and response are recorded in it and
+// its delivery ID is signed.
+func (er *Errorly) DoWebhook(webhook *structs.Webhook, payload structs.WebhookMessage,
+	createdAt time.Time, attempt *structs.WebhookDeliveryAttempt) (ok bool, err error) {
+	// Test webhooks are not queued so they are given their own delivery ID.
+	if attempt == nil {
+		attempt = &structs.WebhookDeliveryAttempt{
+			DeliveryID: er.IDGen.GenerateID(),
 		}
 	}
 
-	body := bytes.NewBuffer(res)
+	req, err := er.FormatWebhook(webhook, payload, attempt.DeliveryID, createdAt)
+	if err != nil {
+		// The error is not wrapped as a payload that cannot be formatted
+		// should not be retried.
+		err = xerrors.Errorf("Failed to format payload: %v", err)
+		attempt.Error = err.Error()
 
-	var secret string
+		return false, err
+	}
+
+	// The webhook type does not support this payload.
+	if req == nil {
+		return true, nil
+	}
+
+	attempt.RequestBody = string(req.Body)
 
+	deliveryID := strconv.FormatInt(attempt.DeliveryID, 10)
+
+	req.Header.Set(signature.DeliveryHeader, deliveryID)
+
+	secrets := webhookSecrets(webhook)
+	if len(secrets) > 0 {
+		req.Header.Set(signature.SignatureHeader, signature.Format(time.Now(), deliveryID, req.Body, secrets...))
+	}
+
+	// X-Errorly-Secret is kept for receivers that have not moved to
+	// X-Errorly-Signature. It does not protect against replays.
 	if webhook.Secret != "" {
-		secret, err = er.generateSecret(bytes.NewBuffer(res), webhook.Secret)
+		secret, err := er.generateSecret(bytes.NewBuffer(req.Body), webhook.Secret)
 		if err != nil {
 			er.Logger.Warn().Err(err).Msg("Failed to generate webhook secret")
+		} else {
+			req.Header.Set("X-Errorly-Secret", secret)
 		}
 	}
 
-	return er.ExecuteWebhook(webhook, body, secret)
+	return er.ExecuteWebhook(req, attempt)
 }
 
-// ExecuteWebhook executes a webhook.
-func (er *Errorly) ExecuteWebhook(webhook *structs.Webhook, body io.Reader, secret string) (ok bool, err error) {
-	req, err := http.NewRequestWithContext(er.ctx, "POST", webhook.URL, body)
+// Maximum length of a webhook response that is stored in a delivery attempt.
+const maxWebhookResponseLength = 4096
+
+// ExecuteWebhook executes a formatted webhook request. If attempt is not
+// nil, the request headers and response are recorded in it.
+func (er *Errorly) ExecuteWebhook(webhookReq *WebhookRequest,
+	attempt *structs.WebhookDeliveryAttempt) (ok bool, err error) {
+	if attempt == nil {
+		attempt = &structs.WebhookDeliveryAttempt{}
+	}
+
+	attempt.RequestURL = webhookReq.URL
+
+	defer func() {
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(er.ctx, webhookReq.Method, webhookReq.URL, bytes.NewBuffer(webhookReq.Body))
 	if err != nil {
-		return false, xerrors.Errorf("failed to create request: %w", err)
+		// The error is not wrapped as an invalid url should not be retried.
+		return false, xerrors.Errorf("failed to create request: %v", err)
 	}
 
-	// At the moment all requests will be of JSON content
-	// if webhook.Type == structs.DiscordWebhook || webhook.JSONContent {
-	// }
-	req.Header.Set("Content-Type", "application/json")
+	for key := range webhookReq.Header {
+		req.Header.Set(key, webhookReq.Header.Get(key))
+	}
 
-	if secret != "" {
-		req.Header.Set("X-Errorly-Secret", secret)
+	attempt.RequestHeaders = make(map[string]string)
+	for key := range req.Header {
+		attempt.RequestHeaders[key] = req.Header.Get(key)
 	}
 
+	start := time.Now()
+
 	res, err := er.client.Do(req)
+
+	attempt.Latency = time.Since(start).Milliseconds()
+
 	if err != nil {
 		return false, xerrors.Errorf("failed to handle request: %w", err)
 	}
 
 	defer res.Body.Close()
 
-	return res.StatusCode >= 200 && res.StatusCode <= 299, nil
+	attempt.StatusCode = res.StatusCode
+
+	response, readErr := ioutil.ReadAll(io.LimitReader(res.Body, maxWebhookResponseLength))
+	if readErr != nil {
+		er.Logger.Warn().Err(readErr).Msg("Failed to read webhook response")
+	}
+
+	attempt.ResponseBody = strings.ToValidUTF8(string(response), "")
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return false, &WebhookStatusError{StatusCode: res.StatusCode}
+	}
+
+	return true, nil
 }
@@ -0,0 +1,216 @@
+package errorly
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+	"golang.org/x/xerrors"
+)
+
+// WebhookRequest is a webhook payload formatted for a type of webhook.
+type WebhookRequest struct {
+	Method string // Defaults to POST
+	URL    string // Defaults to the url of the webhook
+	Header http.Header
+	Body   []byte
+}
+
+// WebhookFormatter converts webhook messages into requests accepted by a
+// type of webhook. createdAt is when the delivery was created, which stays
+// the same when it is retried. Format returns a nil request if the message
+// should not be sent to the webhook.
+type WebhookFormatter interface {
+	Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+		deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error)
+}
+
+// webhookFormatters contains the formatter used for each WebhookType.
+var webhookFormatters = map[structs.WebhookType]WebhookFormatter{
+	structs.RegularPayload:   regularFormatter{},
+	structs.DiscordWebhook:   discordFormatter{},
+	structs.SlackWebhook:     slackFormatter{},
+	structs.TeamsWebhook:     teamsFormatter{},
+	structs.MatrixWebhook:    matrixFormatter{},
+	structs.TemplateWebhook:  templateFormatter{},
+	structs.PagerDutyWebhook: pagerDutyFormatter{},
+}
+
+// FormatWebhook formats a webhook message for the type of the webhook.
+func (er *Errorly) FormatWebhook(webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	formatter, ok := webhookFormatters[webhook.Type]
+	if !ok {
+		return nil, xerrors.Errorf("Unknown webhook type: %d", webhook.Type)
+	}
+
+	req, err = formatter.Format(er, webhook, payload, deliveryID, createdAt)
+	if err != nil || req == nil {
+		return nil, err
+	}
+
+	if req.Method == "" {
+		req.Method = "POST"
+	}
+
+	if req.URL == "" {
+		req.URL = webhook.URL
+	}
+
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	return req, nil
+}
+
+// jsonRequest returns a request with a JSON encoded body.
+func jsonRequest(v interface{}) (req *WebhookRequest, err error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to marshal payload: %w", err)
+	}
+
+	req = &WebhookRequest{
+		Header: http.Header{},
+		Body:   body,
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// regularFormatter sends the webhook message as is, or as a CloudEvent
+// if the webhook has CloudEvents enabled.
+type regularFormatter struct{}
+
+func (regularFormatter) Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	if webhook.CloudEvents != structs.CloudEventsDisabled {
+		return er.cloudEventRequest(payload, deliveryID, createdAt, webhook.CloudEvents)
+	}
+
+	return jsonRequest(payload)
+}
+
+// discordFormatter sends discord webhook embeds.
+type discordFormatter struct{}
+
+func (discordFormatter) Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	ok, msg := er.ConvertErrorlyToDiscordWebhook(payload)
+	if !ok {
+		return nil, nil
+	}
+
+	return jsonRequest(msg)
+}
+
+// slackFormatter sends slack Block Kit messages.
+type slackFormatter struct{}
+
+func (slackFormatter) Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	ok, msg := er.ConvertErrorlyToSlackWebhook(payload)
+	if !ok {
+		return nil, nil
+	}
+
+	return jsonRequest(msg)
+}
+
+// webhookField is a named value shown alongside a webhook summary.
+type webhookField struct {
+	Name  string
+	Value string
+}
+
+// webhookSummary is the content shared by chat webhook formats.
+type webhookSummary struct {
+	Title       string // Includes the project name
+	URL         string
+	Description string // Markdown
+	Fields      []webhookField
+}
+
+// summarizeWebhookMessage returns the title, link, description and fields
+// shown for a webhook message in chat webhook formats. Returns false if the
+// message should not be shown.
+func (er *Errorly) summarizeWebhookMessage(payload structs.WebhookMessage) (summary *webhookSummary, ok bool) {
+	if payload.Project == nil || payload.Issue == nil {
+		return nil, false
+	}
+
+	summary = &webhookSummary{
+		URL: fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID),
+		Fields: []webhookField{
+			{Name: "Status", Value: payload.Issue.Type.String()},
+			{Name: "Priority", Value: payload.Issue.Priority.String()},
+		},
+	}
+
+	if payload.Issue.Environment != "" {
+		summary.Fields = append(summary.Fields, webhookField{Name: "Environment", Value: payload.Issue.Environment})
+	}
+
+	var title string
+
+	switch payload.Type {
+	case structs.IssueCreate:
+		title = fmt.Sprintf("Issue opened: %s", payload.Issue.Error)
+		summary.Description = payload.Issue.Description
+	case structs.IssueComment:
+		title = fmt.Sprintf("New comment on issue: %s", payload.Issue.Error)
+
+		if payload.Comment != nil && payload.Comment.Content != nil {
+			summary.Description = *payload.Comment.Content
+		}
+	case structs.IssueCommentEdited:
+		title = fmt.Sprintf("Comment edited on issue: %s", payload.Issue.Error)
+
+		if payload.Comment != nil && payload.Comment.Content != nil {
+			summary.Description = *payload.Comment.Content
+		}
+	case structs.IssueCommentDeleted:
+		title = fmt.Sprintf("Comment deleted on issue: %s", payload.Issue.Error)
+	case structs.IssueStarred:
+		// If the issue was unstarred, do not show as a webhook message
+		if !payload.Issue.Starred {
+			return nil, false
+		}
+
+		title = fmt.Sprintf("New star added to %s", payload.Issue.Error)
+	case structs.IssueAssigned:
+		if payload.Issue.AssigneeID == 0 || payload.Issue.Assignee == nil {
+			title = fmt.Sprintf("Issue %s has been unassigned", payload.Issue.Error)
+		} else {
+			title = fmt.Sprintf("Issue %s assigned to %s", payload.Issue.Error, payload.Issue.Assignee.Name)
+		}
+	case structs.IssueLocked:
+		if payload.Issue.CommentsLocked {
+			title = fmt.Sprintf("Issue %s has been locked", payload.Issue.Error)
+		} else {
+			title = fmt.Sprintf("Issue %s has been unlocked", payload.Issue.Error)
+		}
+	case structs.IssueMarkStatus:
+		title = fmt.Sprintf("Issue %s has been marked %s", payload.Issue.Error, payload.Issue.Type.String())
+	case structs.IssueLabeled:
+		title = fmt.Sprintf("Labels changed on issue %s", payload.Issue.Error)
+
+		if len(payload.AddedLabels) > 0 {
+			summary.Fields = append(summary.Fields, webhookField{Name: "Added", Value: labelNames(payload.AddedLabels)})
+		}
+
+		if len(payload.RemovedLabels) > 0 {
+			summary.Fields = append(summary.Fields, webhookField{Name: "Removed", Value: labelNames(payload.RemovedLabels)})
+		}
+	default:
+		return nil, false
+	}
+
+	summary.Title = fmt.Sprintf("[%s] %s", payload.Project.Settings.DisplayName, title)
+
+	return summary, true
+}
@@ -7,8 +7,12 @@ import (
 	"errors"
 	"io/ioutil"
 	"math"
+	"net"
 	"net/http"
 	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,8 +27,16 @@ import (
 	"golang.org/x/xerrors"
 )
 
-// Issues per page.
-const pageLimit = 25 // TODO: Move to webapp
+// Issues and comments per page when no limit is passed.
+const pageLimit = 25
+
+// Maximum issues and comments that can be requested per page.
+const maxPageLimit = 100
+
+// Maximum length of a label name.
+const maxLabelNameLength = 32
+
+var labelColorRegex = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
 
 // func parseJSONForm(r *http.Request) (vars map[string]string, err error) {
 // 	err = json.NewDecoder(r.Body).Decode(&vars)
@@ -161,16 +173,10 @@ func parseSorting(s string) string {
 	return "ASC"
 }
 
-func fetchProjectIssues(er *Errorly, projectID int64, limit int, page int,
-	query string, userID int64) (issues []structs.IssueEntry, totalissues int, err error) {
-	// sort:created_by-desc
-	_issues := make([]structs.IssueEntry, 0, limit)
-
-	initialQuery := er.Postgres.Model(&_issues).
-		Where("issue_entry.project_id = ?", projectID).
-		Order("starred DESC")
-
-	parts := []string{}
+// splitIssueQuery splits a search query into its space separated parts.
+// Single quotes are treated the same as double quotes.
+func splitIssueQuery(query string) (parts []string, err error) {
+	parts = []string{}
 	query = strings.ReplaceAll(query, `'`, `"`)
 
 	if len(query) > 0 {
@@ -183,14 +189,147 @@ func fetchProjectIssues(er *Errorly, projectID int64, limit int, page int,
 		}
 	}
 
+	return parts, nil
+}
+
+// fetchSavedSearch returns a saved search from a project. Searches that have
+// not been shared are only returned to the user who created them.
+func fetchSavedSearch(er *Errorly, projectID int64, searchID int64, userID int64) (search *structs.SavedSearch, err error) {
+	search = &structs.SavedSearch{}
+
+	err = er.Postgres.Model(search).
+		Where("project_id = ?", projectID).
+		Where("id = ?", searchID).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("shared = ?", true).WhereOr("created_by_id = ?", userID)
+
+			return q, nil
+		}).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return search, nil
+}
+
+// issueSort is a single ordering used when fetching issues.
+type issueSort struct {
+	Key  string
+	Desc bool
+}
+
+// issueSearch is a parsed issue query. Filters can be applied to any issue
+// query which allows the same search to be used for counting.
+type issueSearch struct {
+	filters []func(q *orm.Query) *orm.Query
+	sorts   []issueSort
+}
+
+// issueSortColumns contains the keys that issues can be sorted by and the
+// expression used to order them. Nullable columns are coalesced so they
+// can be compared against when paginating with a cursor.
+var issueSortColumns = map[string]string{
+	"id":            "issue_entry.id",
+	"starred":       "issue_entry.starred",
+	"type":          "COALESCE(issue_entry.type, 0)",
+	"occurrences":   "COALESCE(issue_entry.occurrences, 0)",
+	"assignee_id":   "COALESCE(issue_entry.assignee_id, 0)",
+	"error":         "COALESCE(issue_entry.error, '')",
+	"function":      "COALESCE(issue_entry.function, '')",
+	"checkpoint":    "COALESCE(issue_entry.checkpoint, '')",
+	"last_modified": "issue_entry.last_modified",
+	"last_seen":     "COALESCE(issue_entry.last_seen, issue_entry.created_at)",
+	"created_at":    "issue_entry.created_at",
+	"comment_count": "issue_entry.comment_count",
+	"environment":   "COALESCE(issue_entry.environment, '')",
+	"release":       "COALESCE(issue_entry.release, '')",
+	"priority":      "COALESCE(issue_entry.priority, 0)",
+	"reactions":     "COALESCE(issue_entry.reaction_count, 0)",
+}
+
+// issueSortValue returns the value of an issue for a sort key as it would
+// be compared in postgres.
+func issueSortValue(issue structs.IssueEntry, key string) string {
+	switch key {
+	case "id":
+		return strconv.FormatInt(issue.ID, 10)
+	case "starred":
+		return strconv.FormatBool(issue.Starred)
+	case "type":
+		return strconv.Itoa(int(issue.Type))
+	case "occurrences":
+		return strconv.Itoa(issue.Occurrences)
+	case "assignee_id":
+		return strconv.FormatInt(issue.AssigneeID, 10)
+	case "error":
+		return issue.Error
+	case "function":
+		return issue.Function
+	case "checkpoint":
+		return issue.Checkpoint
+	case "last_modified":
+		return issue.LastModified.Format(time.RFC3339Nano)
+	case "last_seen":
+		if issue.LastSeen.IsZero() {
+			return issue.CreatedAt.Format(time.RFC3339Nano)
+		}
+
+		return issue.LastSeen.Format(time.RFC3339Nano)
+	case "created_at":
+		return issue.CreatedAt.Format(time.RFC3339Nano)
+	case "comment_count":
+		return strconv.FormatInt(issue.CommentCount, 10)
+	case "environment":
+		return issue.Environment
+	case "release":
+		return issue.Release
+	case "priority":
+		return strconv.Itoa(int(issue.Priority))
+	case "reactions":
+		return strconv.Itoa(issue.ReactionCount)
+	}
+
+	return ""
+}
+
+// parseIssueQuery converts a search query into filters and orderings.
+func parseIssueQuery(query string, userID int64) (search *issueSearch, err error) {
+	// sort:created_by-desc
+	search = &issueSearch{
+		filters: make([]func(q *orm.Query) *orm.Query, 0),
+		sorts: []issueSort{
+			{Key: "starred", Desc: true},
+		},
+	}
+
+	where := func(condition string, params ...interface{}) {
+		search.filters = append(search.filters, func(q *orm.Query) *orm.Query {
+			return q.Where(condition, params...)
+		})
+	}
+
+	whereGroup := func(fn func(q *orm.Query) (*orm.Query, error)) {
+		search.filters = append(search.filters, func(q *orm.Query) *orm.Query {
+			return q.WhereGroup(fn)
+		})
+	}
+
+	parts, err := splitIssueQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
 	// // fetchStarred := false
 	// fuzzyEntries := make([]string, 0)
 
 	for _, part := range parts {
-		subpart := strings.Split(part, ":")
-		// if len(subpart) < 2 {
-		// 	fuzzyEntries = append(fuzzyEntries, subpart[0])
-		// } else {
+		subpart := strings.SplitN(part, ":", 2)
+		if len(subpart) < 2 {
+			// fuzzyEntries = append(fuzzyEntries, subpart[0])
+			continue
+		}
+
 		finger, thumb := subpart[0], subpart[1]
 		switch finger {
 		// new query's
@@ -206,76 +345,610 @@ func fetchProjectIssues(er *Errorly, projectID int64, limit int, page int,
 		// 	// star
 		case "sort":
 			thumbvalues := strings.Split(thumb, "-")
-			if len(thumbvalues) >= 1 {
-				if len(thumbvalues) == 1 {
-					thumbvalues = append(thumbvalues, "DESC")
-				}
+			if len(thumbvalues) == 1 {
+				thumbvalues = append(thumbvalues, "DESC")
+			}
+
+			key := strings.ToLower(thumbvalues[0])
+			if _, ok := issueSortColumns[key]; !ok || key == "id" {
+				continue
+			}
 
-				switch thumb := strings.ToLower(thumbvalues[0]); thumb {
-				case "starred", "type", "occurrences", "assignee_id", "error", "function",
-					"checkpoint", "last_modified", "created_at", "comment_count":
-					initialQuery = initialQuery.Order(thumb + " " + parseSorting(thumbvalues[1]))
+			duplicate := false
+
+			for _, sort := range search.sorts {
+				if sort.Key == key {
+					duplicate = true
 				}
 			}
+
+			if !duplicate {
+				search.sorts = append(search.sorts, issueSort{
+					Key:  key,
+					Desc: parseSorting(thumbvalues[1]) == "DESC",
+				})
+			}
 		case "is":
 			switch strings.ToLower(thumb) {
 			case "active":
-				// initialQuery = initialQuery.Where("type = ?", structs.EntryActive)
-				initialQuery = initialQuery.Where("type is NULL", structs.EntryActive)
+				// where("type = ?", structs.EntryActive)
+				where("type is NULL")
 			case "open":
-				initialQuery = initialQuery.Where("type = ?", structs.EntryOpen)
+				where("type = ?", structs.EntryOpen)
 			case "invalid":
-				initialQuery = initialQuery.Where("type = ?", structs.EntryInvalid)
+				where("type = ?", structs.EntryInvalid)
 			case "resolved":
-				initialQuery = initialQuery.Where("type = ?", structs.EntryResolved)
+				where("type = ?", structs.EntryResolved)
+			case "snoozed":
+				where("type = ?", structs.EntrySnoozed)
 			case "starred":
-				initialQuery = initialQuery.Where("starred = ?", true)
+				where("starred = ?", true)
+			}
+		case "label":
+			where("issue_entry.id IN (SELECT issue_label.issue_id FROM issue_labels AS issue_label "+
+				"JOIN labels AS label ON label.id = issue_label.label_id "+
+				"WHERE label.project_id = issue_entry.project_id AND lower(label.name) = lower(?))", thumb)
+		case "env", "environment":
+			where("environment = ?", thumb)
+		case "release":
+			where("release = ?", thumb)
+		case "priority":
+			priority, err := structs.ParsePriority(strings.ToLower(thumb))
+			if err == nil {
+				where("COALESCE(priority, 0) = ?", priority)
 			}
 		case "author", "from":
 			switch strings.ToLower(thumb) {
 			case "@me":
 				if userID != 0 {
-					initialQuery = initialQuery.Where("created_by_id = ?", userID)
+					where("created_by_id = ?", userID)
 				}
 			case "no":
-				initialQuery = initialQuery.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+				whereGroup(func(q *orm.Query) (*orm.Query, error) {
 					q = q.WhereOr("created_by_id = ?", 0).WhereOr("created_by_id IS NULL")
 
 					return q, nil
 				})
 			default:
-				id, err := strconv.Atoi(thumb)
+				id, err := strconv.ParseInt(thumb, 10, 64)
 				if err == nil {
-					initialQuery = initialQuery.Where("created_by_id = ?", id)
+					where("created_by_id = ?", id)
 				}
 			}
 		case "assigned", "assignee":
 			switch strings.ToLower(thumb) {
 			case "@me":
 				if userID != 0 {
-					initialQuery = initialQuery.Where("assignee_id = ?", userID)
+					where("assignee_id = ?", userID)
 				}
 			case "no":
-				initialQuery = initialQuery.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+				whereGroup(func(q *orm.Query) (*orm.Query, error) {
 					q = q.WhereOr("assignee_id = ?", 0).WhereOr("assignee_id IS NULL")
 
 					return q, nil
 				})
 			default:
-				id, err := strconv.Atoi(thumb)
+				id, err := strconv.ParseInt(thumb, 10, 64)
 				if err == nil {
-					initialQuery = initialQuery.Where("assignee_id = ?", id)
+					where("assignee_id = ?", id)
 				}
 			}
 		}
 	}
 
-	count, err := initialQuery.Limit(limit).Offset(int(math.Max(0, float64(limit*page)))).SelectAndCount()
+	// Snowflake IDs are unique so they are used to break any ties.
+	search.sorts = append(search.sorts, issueSort{Key: "id", Desc: true})
+
+	return search, nil
+}
+
+// Apply adds the search filters to a query.
+func (s *issueSearch) Apply(q *orm.Query) *orm.Query {
+	for _, filter := range s.filters {
+		q = filter(q)
+	}
+
+	return q
+}
+
+// Keys returns the keys the search is ordered by.
+func (s *issueSearch) Keys() []string {
+	keys := make([]string, 0, len(s.sorts))
+	for _, sort := range s.sorts {
+		keys = append(keys, sort.Key)
+	}
+
+	return keys
+}
+
+// applyCursor only includes rows that come after the values in a cursor
+// for the orderings passed.
+func applyCursor(q *orm.Query, columns []string, desc []bool, values []interface{}) *orm.Query {
+	return q.WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+		for i := range columns {
+			q = q.WhereOrGroup(func(q *orm.Query) (*orm.Query, error) {
+				for j := 0; j < i; j++ {
+					q = q.Where(columns[j]+" = ?", values[j])
+				}
+
+				if desc[i] {
+					q = q.Where(columns[i]+" < ?", values[i])
+				} else {
+					q = q.Where(columns[i]+" > ?", values[i])
+				}
+
+				return q, nil
+			})
+		}
+
+		return q, nil
+	})
+}
+
+func fetchProjectIssues(er *Errorly, projectID int64, limit int, page int, cursor string,
+	query string, userID int64) (issues []structs.IssueEntry, totalissues int, nextCursor string, err error) {
+	search, err := parseIssueQuery(query, userID)
+	if err != nil {
+		return
+	}
+
+	_issues := make([]structs.IssueEntry, 0, limit+1)
+
+	initialQuery := search.Apply(er.Postgres.Model(&_issues).
+		Where("issue_entry.project_id = ?", projectID))
+
+	count, err := initialQuery.Clone().Count()
+	if err != nil {
+		return
+	}
+
+	keys := search.Keys()
+	columns := make([]string, 0, len(search.sorts))
+	desc := make([]bool, 0, len(search.sorts))
+
+	for _, sort := range search.sorts {
+		columns = append(columns, issueSortColumns[sort.Key])
+		desc = append(desc, sort.Desc)
+
+		if sort.Desc {
+			initialQuery = initialQuery.OrderExpr(issueSortColumns[sort.Key] + " DESC")
+		} else {
+			initialQuery = initialQuery.OrderExpr(issueSortColumns[sort.Key] + " ASC")
+		}
+	}
+
+	if cursor != "" {
+		values, err := decodeCursor(cursor, keys)
+		if err != nil {
+			return nil, 0, "", err
+		}
+
+		initialQuery = applyCursor(initialQuery, columns, desc, values)
+	} else {
+		initialQuery = initialQuery.Offset(int(math.Max(0, float64(limit*page))))
+	}
+
+	// We will fetch an extra issue to know if there are any more pages.
+	err = initialQuery.Relation("Labels").Limit(limit + 1).Select()
+	if err != nil {
+		return
+	}
+
+	if len(_issues) > limit {
+		_issues = _issues[:limit]
+
+		last := _issues[len(_issues)-1]
+		values := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			values = append(values, issueSortValue(last, key))
+		}
+
+		nextCursor = encodeCursor(keys, values)
+	}
+
+	return _issues, count, nextCursor, nil
+}
+
+// issueFacetColumns contains the expressions issues are grouped by when
+// counting facets.
+var issueFacetColumns = map[string]string{
+	"status":      "COALESCE(issue_entry.type, 0)::text",
+	"assignee":    "COALESCE(issue_entry.assignee_id, 0)::text",
+	"starred":     "issue_entry.starred::text",
+	"environment": "COALESCE(issue_entry.environment, '')",
+	"release":     "COALESCE(issue_entry.release, '')",
+}
+
+// fetchProjectIssueFacets counts the issues matching a query grouped by status,
+// assignee, starred, environment and release. The same filters as
+// fetchProjectIssues are used.
+func fetchProjectIssueFacets(er *Errorly, projectID int64,
+	query string, userID int64) (facets *structs.APIProjectIssueFacets, err error) {
+	search, err := parseIssueQuery(query, userID)
 	if err != nil {
 		return
 	}
 
-	return _issues, count, nil
+	facets = &structs.APIProjectIssueFacets{
+		Status:      make(map[string]int),
+		Assignee:    make(map[int64]int),
+		Environment: make(map[string]int),
+		Release:     make(map[string]int),
+	}
+
+	for facet, column := range issueFacetColumns {
+		rows := make([]struct {
+			Value string
+			Count int
+		}, 0)
+
+		err = search.Apply(er.Postgres.Model((*structs.IssueEntry)(nil)).
+			Where("issue_entry.project_id = ?", projectID)).
+			ColumnExpr(column + " AS value").
+			ColumnExpr("count(*) AS count").
+			GroupExpr(column).
+			Select(&rows)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			switch facet {
+			case "status":
+				entryType, err := strconv.ParseUint(row.Value, 10, 8)
+				if err == nil {
+					facets.Status[structs.EntryType(entryType).String()] += row.Count
+				}
+			case "assignee":
+				assigneeID, err := strconv.ParseInt(row.Value, 10, 64)
+				if err == nil {
+					facets.Assignee[assigneeID] += row.Count
+				}
+			case "starred":
+				if row.Value == "true" {
+					facets.Starred += row.Count
+				}
+			case "environment":
+				facets.Environment[row.Value] += row.Count
+			case "release":
+				facets.Release[row.Value] += row.Count
+			}
+		}
+	}
+
+	return facets, nil
+}
+
+// recordAffectedUser stores a user who has encountered an issue. Returns true
+// if the user had not encountered the issue before.
+func recordAffectedUser(er *Errorly, issueID int64, identifier string) (bool, error) {
+	affectedUser := &structs.AffectedUser{
+		ID:         er.IDGen.GenerateID(),
+		IssueID:    issueID,
+		Identifier: identifier,
+		FirstSeen:  time.Now().UTC(),
+	}
+
+	res, err := er.Postgres.Model(affectedUser).
+		OnConflict("DO NOTHING").
+		Insert()
+	if err != nil {
+		return false, xerrors.Errorf("Failed to insert affected user: %w", err)
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// isValidLabelName returns true if a label name can be used. Label names
+// cannot contain spaces so they can be searched with label:name.
+func isValidLabelName(name string) bool {
+	return len(name) > 0 && len(name) <= maxLabelNameLength && !strings.ContainsAny(name, " \t\n\"'")
+}
+
+// matchPriorityRules returns the priority of the first rule whose pattern
+// matches the issue error. Returns PriorityLow if no rules match.
+func matchPriorityRules(rules []structs.PriorityRule, issueError string) structs.Priority {
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			continue
+		}
+
+		if pattern.MatchString(issueError) {
+			return rule.Priority
+		}
+	}
+
+	return structs.PriorityLow
+}
+
+// recordRelease stores the first time a release of a project was seen.
+func recordRelease(er *Errorly, projectID int64, version string) error {
+	release := &structs.Release{
+		ID:        er.IDGen.GenerateID(),
+		ProjectID: projectID,
+		Version:   version,
+		FirstSeen: time.Now().UTC(),
+	}
+
+	_, err := er.Postgres.Model(release).
+		OnConflict("DO NOTHING").
+		Insert()
+	if err != nil {
+		return xerrors.Errorf("Failed to insert release: %w", err)
+	}
+
+	return nil
+}
+
+// isRegression returns true if an occurrence in a release should reopen a
+// resolved issue. Issues resolved in a release are only reopened by
+// occurrences in the fixing release or any release seen after it.
+func isRegression(er *Errorly, projectID int64, issue *structs.IssueEntry, version string) (bool, error) {
+	// Without knowing either release we cannot tell if the occurrence is
+	// from before the fix.
+	if issue.ResolvedInRelease == "" || version == "" {
+		return true, nil
+	}
+
+	if version == issue.ResolvedInRelease {
+		// When resolved in the next release, the release it was resolved
+		// in is still expected to have the issue.
+		return !issue.ResolvedInNextRelease, nil
+	}
+
+	releases := make([]structs.Release, 0, 2)
+
+	err := er.Postgres.Model(&releases).
+		Where("project_id = ?", projectID).
+		WhereIn("version IN (?)", []string{version, issue.ResolvedInRelease}).
+		Select()
+	if err != nil {
+		return false, xerrors.Errorf("Failed to fetch releases: %w", err)
+	}
+
+	var resolvedRelease, occurrenceRelease *structs.Release
+
+	for i := range releases {
+		switch releases[i].Version {
+		case issue.ResolvedInRelease:
+			resolvedRelease = &releases[i]
+		case version:
+			occurrenceRelease = &releases[i]
+		}
+	}
+
+	if resolvedRelease == nil || occurrenceRelease == nil {
+		return true, nil
+	}
+
+	// Release IDs are snowflakes so are ordered by when they were first seen.
+	return occurrenceRelease.ID > resolvedRelease.ID, nil
+}
+
+// issueActivityValue returns the value of an issue an action changes so it
+// can be recorded in the issue activity.
+func issueActivityValue(action structs.ActionType, issue *structs.IssueEntry) interface{} {
+	switch action {
+	case structs.ActionStar:
+		return issue.Starred
+	case structs.ActionAssign:
+		return issue.AssigneeID
+	case structs.ActionLockComments:
+		return issue.CommentsLocked
+	case structs.ActionMarkStatus:
+		return map[string]interface{}{
+			"type":                     issue.Type,
+			"resolved_in_release":      issue.ResolvedInRelease,
+			"resolved_in_next_release": issue.ResolvedInNextRelease,
+		}
+	case structs.ActionSnooze:
+		return map[string]interface{}{
+			"type":               issue.Type,
+			"snoozed_until":      issue.SnoozedUntil,
+			"snooze_occurrences": issue.SnoozeOccurrences,
+			"snooze_users":       issue.SnoozeUsers,
+		}
+	case structs.ActionLabel:
+		labelIDs := make([]int64, 0, len(issue.Labels))
+		for _, label := range issue.Labels {
+			labelIDs = append(labelIDs, label.ID)
+		}
+
+		sort.Slice(labelIDs, func(i, j int) bool { return labelIDs[i] < labelIDs[j] })
+
+		return labelIDs
+	case structs.ActionPriority:
+		return issue.Priority
+	}
+
+	return nil
+}
+
+// commentedActions are the actions that also create a system comment on
+// the issue. Their activity is not shown in the timeline as the comment
+// already shows the change.
+var commentedActions = []structs.ActionType{
+	structs.ActionStar,
+	structs.ActionAssign,
+	structs.ActionLockComments,
+	structs.ActionMarkStatus,
+	structs.ActionSnooze,
+}
+
+// recordActivity records an action performed on an issue. Actions which
+// did not change the issue are not recorded.
+func recordActivity(er *Errorly, issue *structs.IssueEntry, actor *structs.User,
+	action structs.ActionType, before interface{}, after interface{}) (err error) {
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	activity := &structs.Activity{
+		ID:        er.IDGen.GenerateID(),
+		ProjectID: issue.ProjectID,
+		IssueID:   issue.ID,
+		CreatedAt: time.Now().UTC(),
+		ActorID:   actor.ID,
+		Action:    action,
+		Before:    before,
+		After:     after,
+	}
+
+	_, err = er.Postgres.Model(activity).Insert()
+	if err != nil {
+		return xerrors.Errorf("Failed to record activity: %w", err)
+	}
+
+	return nil
+}
+
+// auditRedactedFields are fields whose values are never stored in the
+// audit log.
+var auditRedactedFields = map[string]bool{
+	"secret":          true,
+	"previous_secret": true,
+	"routing_key":     true,
+	"token":           true,
+}
+
+// auditDiff returns the fields that differ between the JSON representation
+// of two values. Either value may be nil.
+func auditDiff(before interface{}, after interface{}) (diff map[string]structs.AuditChange, err error) {
+	fields := func(value interface{}) (map[string]interface{}, error) {
+		_fields := make(map[string]interface{})
+		if value == nil {
+			return _fields, nil
+		}
+
+		res, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return _fields, json.Unmarshal(res, &_fields)
+	}
+
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to marshal before: %w", err)
+	}
+
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to marshal after: %w", err)
+	}
+
+	diff = make(map[string]structs.AuditChange)
+
+	for key := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			beforeFields[key] = nil
+		}
+	}
+
+	for key, beforeValue := range beforeFields {
+		afterValue := afterFields[key]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+
+		if auditRedactedFields[key] {
+			beforeValue, afterValue = beforeValue != nil && beforeValue != "", afterValue != nil && afterValue != ""
+		}
+
+		diff[key] = structs.AuditChange{
+			Before: beforeValue,
+			After:  afterValue,
+		}
+	}
+
+	return diff, nil
+}
+
+// requestIP returns the IP address of the client that made a request.
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// recordAudit records a change made to a project in its audit log.
+func recordAudit(er *Errorly, r *http.Request, project *structs.Project, actor *structs.User,
+	action structs.AuditAction, target string, before interface{}, after interface{}) (err error) {
+	diff, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry := &structs.AuditLogEntry{
+		ID:        er.IDGen.GenerateID(),
+		ProjectID: project.ID,
+		CreatedAt: time.Now().UTC(),
+		Action:    action,
+		Target:    target,
+		IPAddress: requestIP(r),
+		UserAgent: r.UserAgent(),
+		Diff:      diff,
+	}
+
+	if actor != nil {
+		entry.ActorID = actor.ID
+	}
+
+	_, err = er.Postgres.Model(entry).Insert()
+	if err != nil {
+		return xerrors.Errorf("Failed to record audit log: %w", err)
+	}
+
+	return nil
+}
+
+// adjustIssueCounters updates the cached issue counters of a project when an
+// issue changes status. The project still has to be updated afterwards.
+func adjustIssueCounters(project *structs.Project, from structs.EntryType, to structs.EntryType) {
+	switch from {
+	case structs.EntryActive:
+		project.ActiveIssues--
+	case structs.EntryOpen:
+		project.OpenIssues--
+	case structs.EntryResolved:
+		project.ResolvedIssues--
+	case structs.EntryInvalid, structs.EntrySnoozed:
+	}
+
+	switch to {
+	case structs.EntryActive:
+		project.ActiveIssues++
+	case structs.EntryOpen:
+		project.OpenIssues++
+	case structs.EntryResolved:
+		project.ResolvedIssues++
+	case structs.EntryInvalid, structs.EntrySnoozed:
+	}
+}
+
+// parsePageLimit converts a limit argument into the number of results per
+// page. The default limit is used when empty and is capped at maxPageLimit.
+func parsePageLimit(_limit string) (limit int, err error) {
+	if _limit == "" {
+		return pageLimit, nil
+	}
+
+	limit, err = strconv.Atoi(_limit)
+	if err != nil || limit < 1 {
+		return 0, xerrors.Errorf("invalid limit '%s'", _limit)
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return limit, nil
 }
 
 func isContributor(project *structs.Project, id int64) bool {
@@ -552,20 +1225,13 @@ func APIMeHandler(er *Errorly) http.HandlerFunc {
 	}
 }
 
-// APIProjectCreateHandler creates a new project and
-// returns the project made.
-func APIProjectCreateHandler(er *Errorly) http.HandlerFunc {
+// APIMeNotificationsHandler returns the notifications of the user, newest
+// first. Passing unread=true will only return unread notifications.
+func APIMeNotificationsHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
-		if err := r.ParseForm(); err != nil {
-			er.Logger.Error().Err(err).Msg("Failed to parse form")
-			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
-
-			return
-		}
-
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
 		if !auth {
@@ -574,20 +1240,238 @@ func APIProjectCreateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		projectName := r.PostFormValue("display_name")
-		if len(projectName) < 3 {
-			passResponse(rw, "Invalid name was passed", false, http.StatusBadRequest)
+		limit, err := parsePageLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			passResponse(rw, "Limit argument is not valid", false, http.StatusBadRequest)
 
 			return
 		}
 
-		projectURL := r.PostFormValue("url")
-		if projectURL != "" {
-			_, err := url.Parse(projectURL)
-			if err != nil {
-				passResponse(rw, "Invalid URL was passed", false, http.StatusBadRequest)
-			}
-		}
+		unreadOnly, _ := strconv.ParseBool(r.URL.Query().Get("unread"))
+
+		notifications := make([]structs.Notification, 0, limit+1)
+
+		query := er.Postgres.Model(&notifications).
+			Relation("Issue").
+			Relation("Author").
+			Where("notification.user_id = ?", user.ID).
+			Order("notification.id DESC")
+
+		if unreadOnly {
+			query = query.Where("notification.read = ?", false)
+		}
+
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			values, err := decodeCursor(cursor, []string{"id"})
+			if err != nil {
+				passResponse(rw, "Cursor argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			query = applyCursor(query, []string{"notification.id"}, []bool{true}, values)
+		}
+
+		// We will fetch an extra notification to know if there are any more pages.
+		err = query.Limit(limit + 1).Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		var nextCursor string
+
+		if len(notifications) > limit {
+			notifications = notifications[:limit]
+			nextCursor = encodeCursor([]string{"id"}, []string{
+				strconv.FormatInt(notifications[len(notifications)-1].ID, 10),
+			})
+		}
+
+		unread, err := er.Postgres.Model(&structs.Notification{}).
+			Where("user_id = ?", user.ID).
+			Where("read = ?", false).
+			Count()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, structs.APIMeNotifications{
+			Limit:         limit,
+			NextCursor:    nextCursor,
+			Unread:        unread,
+			Webhook:       user.NotificationWebhook,
+			Notifications: notifications,
+		}, true, http.StatusOK)
+	}
+}
+
+// APIMeNotificationsReadHandler marks notifications as read or unread. If
+// no notifications are passed, every notification of the user is marked.
+func APIMeNotificationsReadHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		read := true
+
+		if _read := r.FormValue("read"); _read != "" {
+			var err error
+
+			read, err = strconv.ParseBool(_read)
+			if err != nil {
+				passResponse(rw, "Read argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+		}
+
+		_notificationIDs, err := qs.Unmarshal(r.FormValue("notifications"))
+		if err != nil {
+			passResponse(rw, "Notifications argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		notificationIDs := make([]int64, 0, len(_notificationIDs))
+
+		for _, _id := range _notificationIDs {
+			if _id, ok := _id.(string); ok {
+				id, err := strconv.ParseInt(_id, 10, 64)
+				if err == nil {
+					notificationIDs = append(notificationIDs, id)
+				}
+			}
+		}
+
+		query := er.Postgres.Model(&structs.Notification{}).
+			Set("read = ?", read).
+			Where("user_id = ?", user.ID)
+
+		if len(notificationIDs) > 0 {
+			query = query.WhereIn("id IN (?)", notificationIDs)
+		} else if r.FormValue("notifications") != "" {
+			// Notifications were passed but none were valid, so do not
+			// fall back to marking every notification.
+			passResponse(rw, "Notifications argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		_, err = query.Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, nil, true, http.StatusOK)
+	}
+}
+
+// APIMeNotificationsWebhookHandler sets the discord webhook mentions are
+// delivered to. Passing an empty url will stop delivering mentions.
+func APIMeNotificationsWebhookHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		webhookURL := strings.TrimSpace(r.FormValue("url"))
+		if webhookURL != "" {
+			_url, err := parseDiscordWebhookURL(webhookURL)
+			if err != nil {
+				passResponse(rw, "Webhook url must be a discord webhook", false, http.StatusBadRequest)
+
+				return
+			}
+
+			webhookURL = _url.String()
+		}
+
+		user.NotificationWebhook = webhookURL
+
+		_, err := er.Postgres.Model(user).
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, nil, true, http.StatusOK)
+	}
+}
+
+// APIProjectCreateHandler creates a new project and
+// returns the project made.
+func APIProjectCreateHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		projectName := r.PostFormValue("display_name")
+		if len(projectName) < 3 {
+			passResponse(rw, "Invalid name was passed", false, http.StatusBadRequest)
+
+			return
+		}
+
+		projectURL := r.PostFormValue("url")
+		if projectURL != "" {
+			_, err := url.Parse(projectURL)
+			if err != nil {
+				passResponse(rw, "Invalid URL was passed", false, http.StatusBadRequest)
+			}
+		}
 
 		projectPrivate, err := strconv.ParseBool(r.PostFormValue("private"))
 		if err != nil {
@@ -848,6 +1732,20 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 		// MarkStatus
 		var markType structs.EntryType
 
+		var releaseResolution structs.ReleaseResolution
+		// Label
+		var labeling bool
+
+		labelIDs := make([]int64, 0)
+		// Priority
+		var priority structs.Priority
+		// Snooze
+		var snoozeUntil time.Time
+
+		var snoozeOccurrences int
+
+		var snoozeUsers int
+
 		switch action {
 		case structs.ActionStar:
 			starring, err = strconv.ParseBool(r.FormValue("starring"))
@@ -879,11 +1777,90 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 			}
 		case structs.ActionMarkStatus:
 			markType, err = structs.ParseEntryType(r.FormValue("mark_type"))
-			if err != nil {
+			if err != nil || markType == structs.EntrySnoozed {
 				passResponse(rw, "MarkType argument is not valid", false, http.StatusBadRequest)
 
 				return
 			}
+
+			releaseResolution, err = structs.ParseReleaseResolution(r.FormValue("resolved_in_release"))
+			if err != nil || (releaseResolution != structs.ResolvedNow && markType != structs.EntryResolved) {
+				passResponse(rw, "ResolvedInRelease argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+		case structs.ActionSnooze:
+			if _snoozeUntil := r.FormValue("snooze_until"); _snoozeUntil != "" {
+				// snooze_until is passed as a unix timestamp in seconds
+				until, err := strconv.ParseInt(_snoozeUntil, 10, 64)
+				if err != nil || time.Unix(until, 0).Before(time.Now()) {
+					passResponse(rw, "SnoozeUntil argument is not valid", false, http.StatusBadRequest)
+
+					return
+				}
+
+				snoozeUntil = time.Unix(until, 0).UTC()
+			}
+
+			if _snoozeOccurrences := r.FormValue("snooze_occurrences"); _snoozeOccurrences != "" {
+				snoozeOccurrences, err = strconv.Atoi(_snoozeOccurrences)
+				if err != nil || snoozeOccurrences < 0 {
+					passResponse(rw, "SnoozeOccurrences argument is not valid", false, http.StatusBadRequest)
+
+					return
+				}
+			}
+
+			if _snoozeUsers := r.FormValue("snooze_users"); _snoozeUsers != "" {
+				snoozeUsers, err = strconv.Atoi(_snoozeUsers)
+				if err != nil || snoozeUsers < 0 {
+					passResponse(rw, "SnoozeUsers argument is not valid", false, http.StatusBadRequest)
+
+					return
+				}
+			}
+
+			if snoozeUntil.IsZero() && snoozeOccurrences == 0 && snoozeUsers == 0 {
+				passResponse(rw, "A snooze condition must be passed", false, http.StatusBadRequest)
+
+				return
+			}
+		case structs.ActionLabel:
+			labeling, err = strconv.ParseBool(r.FormValue("labeling"))
+			if err != nil {
+				passResponse(rw, "Labeling argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			_labelIDs, err := qs.Unmarshal(r.FormValue("label_ids"))
+			if err != nil {
+				passResponse(rw, "LabelIDs argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			for _, _id := range _labelIDs {
+				if _id, ok := _id.(string); ok {
+					id, err := strconv.ParseInt(_id, 10, 64)
+					if err == nil {
+						labelIDs = append(labelIDs, id)
+					}
+				}
+			}
+
+			if len(labelIDs) == 0 {
+				passResponse(rw, "LabelIDs argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+		case structs.ActionPriority:
+			priority, err = structs.ParsePriority(r.FormValue("priority"))
+			if err != nil {
+				passResponse(rw, "Priority argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
 		default:
 			passResponse(rw, "Action argument is not valid", false, http.StatusBadRequest)
 
@@ -926,6 +1903,51 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 
 		now := time.Now().UTC()
 
+		labels := make([]*structs.Label, 0, len(labelIDs))
+
+		if len(labelIDs) > 0 {
+			err = er.Postgres.Model(&labels).
+				Where("project_id = ?", project.ID).
+				WhereIn("id IN (?)", labelIDs).
+				Select()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			if len(labels) == 0 {
+				passResponse(rw, "Could not find these labels", false, http.StatusBadRequest)
+
+				return
+			}
+		}
+
+		// Issues can only be resolved in a release if we know which
+		// release they were last seen in.
+		if releaseResolution != structs.ResolvedNow {
+			noRelease, err := er.Postgres.Model((*structs.IssueEntry)(nil)).
+				Where("project_id = ?", project.ID).
+				WhereIn("id IN (?)", issueIDs).
+				WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+					q = q.WhereOr("release = ''").WhereOr("release IS NULL")
+
+					return q, nil
+				}).
+				Exists()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			if noRelease {
+				passResponse(rw, "Issue has no known release", false, http.StatusBadRequest)
+
+				return
+			}
+		}
+
 		for _, issueID := range issueIDs {
 			// Fetch the request
 			issue := structs.IssueEntry{}
@@ -935,6 +1957,7 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 				Where("issue_entry.project_id = ?", project.ID).
 				Where("issue_entry.id = ?", issueID).
 				Relation("Assignee").
+				Relation("Labels").
 				Select() // TODO: convert this to a single request
 			if err != nil {
 				if errors.Is(err, pg.ErrNoRows) {
@@ -965,6 +1988,11 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 
 			println("B", issue.AssigneeID, origionalIssue.AssigneeID)
 
+			before := issueActivityValue(action, &issue)
+
+			addedLabels := make([]*structs.Label, 0)
+			removedLabels := make([]*structs.Label, 0)
+
 			// Handle action passed
 			switch action {
 			case structs.ActionStar:
@@ -995,6 +2023,27 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 					}
 				}
 
+				if issue.Starred != origionalIssue.Starred {
+					// Create comment
+					comment := structs.Comment{
+						ID:          er.IDGen.GenerateID(),
+						IssueID:     issue.ID,
+						CreatedAt:   now,
+						CreatedByID: user.ID,
+						Type:        structs.StarChanged,
+						Starred:     &starring,
+					}
+
+					_, err = er.Postgres.Model(&comment).Insert()
+					if err != nil {
+						passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+						return
+					}
+
+					issue.CommentCount++
+				}
+
 			case structs.ActionAssign:
 				if assigning {
 					issue.AssigneeID = assigneeID
@@ -1025,16 +2074,39 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 
 					issue.Assignee = assignee
 				}
-			case structs.ActionLockComments:
-				issue.CommentsLocked = locking
 
-				// Create comment
-				comment := structs.Comment{
-					ID:             er.IDGen.GenerateID(),
-					IssueID:        issue.ID,
-					CreatedAt:      now,
-					CreatedByID:    user.ID,
-					Type:           structs.CommentsLocked,
+				if issue.AssigneeID != origionalIssue.AssigneeID {
+					newAssigneeID := issue.AssigneeID
+
+					// Create comment
+					comment := structs.Comment{
+						ID:          er.IDGen.GenerateID(),
+						IssueID:     issue.ID,
+						CreatedAt:   now,
+						CreatedByID: user.ID,
+						Type:        structs.AssigneeChanged,
+						AssigneeID:  &newAssigneeID,
+					}
+
+					_, err = er.Postgres.Model(&comment).Insert()
+					if err != nil {
+						passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+						return
+					}
+
+					issue.CommentCount++
+				}
+			case structs.ActionLockComments:
+				issue.CommentsLocked = locking
+
+				// Create comment
+				comment := structs.Comment{
+					ID:             er.IDGen.GenerateID(),
+					IssueID:        issue.ID,
+					CreatedAt:      now,
+					CreatedByID:    user.ID,
+					Type:           structs.CommentsLocked,
 					CommentsOpened: &locking,
 				}
 
@@ -1046,27 +2118,98 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 				}
 				issue.CommentCount++
 
-			case structs.ActionMarkStatus:
-				switch issue.Type {
-				case structs.EntryActive:
-					project.ActiveIssues--
-				case structs.EntryOpen:
-					project.OpenIssues--
-				case structs.EntryResolved:
-					project.ResolvedIssues--
-				case structs.EntryInvalid:
+			case structs.ActionLabel:
+				issueLabels := make(map[int64]bool)
+				for _, label := range issue.Labels {
+					issueLabels[label.ID] = true
+				}
+
+				for _, label := range labels {
+					switch {
+					case labeling && !issueLabels[label.ID]:
+						_, err = er.Postgres.Model(&structs.IssueLabel{
+							IssueID: issue.ID,
+							LabelID: label.ID,
+						}).OnConflict("DO NOTHING").Insert()
+						if err != nil {
+							passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+							return
+						}
+
+						issue.Labels = append(issue.Labels, label)
+						addedLabels = append(addedLabels, label)
+					case !labeling && issueLabels[label.ID]:
+						_, err = er.Postgres.Model(&structs.IssueLabel{}).
+							Where("issue_id = ?", issue.ID).
+							Where("label_id = ?", label.ID).
+							Delete()
+						if err != nil {
+							passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+							return
+						}
+
+						removedLabels = append(removedLabels, label)
+					}
+				}
+
+				if len(removedLabels) > 0 {
+					_labels := make([]*structs.Label, 0, len(issue.Labels))
+
+					for _, label := range issue.Labels {
+						removed := false
+
+						for _, removedLabel := range removedLabels {
+							if removedLabel.ID == label.ID {
+								removed = true
+							}
+						}
+
+						if !removed {
+							_labels = append(_labels, label)
+						}
+					}
+
+					issue.Labels = _labels
+				}
+
+			case structs.ActionPriority:
+				issue.Priority = priority
+
+			case structs.ActionMarkStatus, structs.ActionSnooze:
+				newType := markType
+				if action == structs.ActionSnooze {
+					newType = structs.EntrySnoozed
+				}
+
+				adjustIssueCounters(project, issue.Type, newType)
+				issue.Type = newType
+
+				// Any previous snooze conditions no longer apply once
+				// the status has changed.
+				issue.SnoozedUntil = time.Time{}
+				issue.SnoozeOccurrences = 0
+				issue.SnoozeUsers = 0
+
+				issue.ResolvedInRelease = ""
+				issue.ResolvedInNextRelease = false
+
+				if releaseResolution != structs.ResolvedNow {
+					issue.ResolvedInRelease = issue.Release
+					issue.ResolvedInNextRelease = releaseResolution == structs.ResolvedInNextRelease
 				}
 
-				issue.Type = markType
+				if action == structs.ActionSnooze {
+					issue.SnoozedUntil = snoozeUntil
 
-				switch issue.Type {
-				case structs.EntryActive:
-					project.ActiveIssues++
-				case structs.EntryOpen:
-					project.OpenIssues++
-				case structs.EntryResolved:
-					project.ResolvedIssues++
-				case structs.EntryInvalid:
+					if snoozeOccurrences > 0 {
+						issue.SnoozeOccurrences = issue.Occurrences + snoozeOccurrences
+					}
+
+					if snoozeUsers > 0 {
+						issue.SnoozeUsers = issue.UsersAffected + snoozeUsers
+					}
 				}
 
 				// Update issues cache counter on project
@@ -1086,7 +2229,7 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 					CreatedAt:   now,
 					CreatedByID: user.ID,
 					Type:        structs.IssueMarked,
-					IssueMarked: &markType,
+					IssueMarked: &newType,
 				}
 
 				_, err = er.Postgres.Model(&comment).Insert()
@@ -1109,6 +2252,13 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 				return
 			}
 
+			err = recordActivity(er, &issue, user, action, before, issueActivityValue(action, &issue))
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
 			if issue.Starred != origionalIssue.Starred {
 				err = er.HandleProjectWebhook(project, structs.WebhookMessage{
 					Type:    structs.IssueStarred,
@@ -1122,6 +2272,18 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 			}
 
 			if issue.AssigneeID != origionalIssue.AssigneeID {
+				if issue.AssigneeID != 0 {
+					err = er.Subscribe(&issue, &structs.User{ID: issue.AssigneeID})
+					if err != nil {
+						er.Logger.Warn().Err(err).Msg("Failed to subscribe assignee")
+					}
+				}
+
+				err = er.NotifySubscribers(project, &issue, structs.IssueAssigned, nil, user)
+				if err != nil {
+					er.Logger.Warn().Err(err).Msg("Failed to notify subscribers")
+				}
+
 				err = er.HandleProjectWebhook(project, structs.WebhookMessage{
 					Type:    structs.IssueAssigned,
 					Project: project,
@@ -1146,6 +2308,11 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 			}
 
 			if issue.Type != origionalIssue.Type {
+				err = er.NotifySubscribers(project, &issue, structs.IssueMarkStatus, nil, user)
+				if err != nil {
+					er.Logger.Warn().Err(err).Msg("Failed to notify subscribers")
+				}
+
 				err = er.HandleProjectWebhook(project, structs.WebhookMessage{
 					Type:    structs.IssueMarkStatus,
 					Project: project,
@@ -1157,6 +2324,20 @@ func APIProjectExecutorHandler(er *Errorly) http.HandlerFunc {
 				}
 			}
 
+			if len(addedLabels) > 0 || len(removedLabels) > 0 {
+				err = er.HandleProjectWebhook(project, structs.WebhookMessage{
+					Type:          structs.IssueLabeled,
+					Project:       project,
+					Issue:         &issue,
+					AddedLabels:   addedLabels,
+					RemovedLabels: removedLabels,
+					Author:        user,
+				})
+				if err != nil {
+					er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+				}
+			}
+
 			issues = append(issues, issue)
 		}
 
@@ -1312,6 +2493,7 @@ func APIProjectContributorsRemoveHandler(er *Errorly) http.HandlerFunc {
 			}
 		}
 
+		originalContributorIDs := project.Settings.ContributorIDs
 		project.Settings.ContributorIDs = contributorIDs
 
 		_, err = er.Postgres.Model(project).
@@ -1323,6 +2505,13 @@ func APIProjectContributorsRemoveHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
+		err = recordAudit(er, r, project, user, structs.AuditContributorRemove, contributorID,
+			map[string][]int64{"contributor_ids": originalContributorIDs},
+			map[string][]int64{"contributor_ids": contributorIDs})
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
 		// Remove project from old contributors project list
 		contributorUser := structs.User{}
 		err = er.Postgres.Model(&contributorUser).
@@ -1444,6 +2633,8 @@ func APIProjectUpdateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
+		originalSettings := project.Settings
+
 		if _displayName := r.FormValue("display_name"); _displayName != "" {
 			_displayName = strings.TrimSpace(_displayName)
 			if len(_displayName) > 3 && _displayName != project.Settings.DisplayName {
@@ -1495,11 +2686,70 @@ func APIProjectUpdateHandler(er *Errorly) http.HandlerFunc {
 			project.Settings.Limited = _limited
 		}
 
+		if _autoResolveDays := r.FormValue("auto_resolve_days"); _autoResolveDays != "" {
+			autoResolveDays, err := strconv.Atoi(_autoResolveDays)
+			if err != nil || autoResolveDays < 0 {
+				passResponse(rw, "AutoResolveDays argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			project.Settings.AutoResolveDays = autoResolveDays
+		}
+
+		if _priorityRules := r.FormValue("priority_rules"); _priorityRules != "" {
+			priorityRules := []structs.PriorityRule{}
+			if err := json.UnmarshalFromString(_priorityRules, &priorityRules); err != nil {
+				passResponse(rw, "PriorityRules argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			for _, rule := range priorityRules {
+				if _, err := regexp.Compile(rule.Pattern); err != nil || rule.Priority.String() == "" {
+					passResponse(rw, "PriorityRules argument is not valid", false, http.StatusBadRequest)
+
+					return
+				}
+			}
+
+			project.Settings.PriorityRules = priorityRules
+		}
+
 		_contributorIDs := []int64{}
 		if err := json.UnmarshalFromString(r.FormValue("contributors"), &_contributorIDs); err != nil {
 			project.Settings.ContributorIDs = _contributorIDs
 		}
 
+		if _defaultSearch := r.FormValue("default_search"); _defaultSearch != "" {
+			defaultSearchID, err := strconv.ParseInt(_defaultSearch, 10, 64)
+			if err != nil {
+				passResponse(rw, "DefaultSearch argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			if defaultSearchID != 0 {
+				// Only shared searches can be used as the default view
+				search, err := fetchSavedSearch(er, project.ID, defaultSearchID, 0)
+				if err != nil {
+					if errors.Is(err, pg.ErrNoRows) {
+						passResponse(rw, "Could not find this search", false, http.StatusBadRequest)
+
+						return
+					}
+
+					passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+					return
+				}
+
+				defaultSearchID = search.ID
+			}
+
+			project.Settings.DefaultSearchID = defaultSearchID
+		}
+
 		_, err := er.Postgres.Model(project).
 			WherePK().
 			Update()
@@ -1509,6 +2759,12 @@ func APIProjectUpdateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
+		err = recordAudit(er, r, project, user, structs.AuditProjectUpdate,
+			strconv.FormatInt(project.ID, 10), originalSettings, project.Settings)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
 		passResponse(rw, structs.APIProjectUpdate{
 			Settings: project.Settings,
 		}, true, http.StatusOK)
@@ -1605,6 +2861,105 @@ func APIProjectDeleteHandler(er *Errorly) http.HandlerFunc {
 
 		println("Removed", results.RowsAffected(), "webhook entries")
 
+		results, err = er.Postgres.Model(&structs.WebhookDelivery{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "webhook delivery entries")
+
+		results, err = er.Postgres.Model(&structs.WebhookDeliveryAttempt{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "webhook delivery attempt entries")
+
+		results, err = er.Postgres.Model(&structs.SavedSearch{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "saved search entries")
+
+		results, err = er.Postgres.Model(&structs.Notification{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "notification entries")
+
+		results, err = er.Postgres.Model(&structs.Reaction{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "reaction entries")
+
+		results, err = er.Postgres.Model(&structs.Activity{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "activity entries")
+
+		results, err = er.Postgres.Model(&structs.AuditLogEntry{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "audit log entries")
+
+		results, err = er.Postgres.Model(&structs.Release{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "release entries")
+
+		results, err = er.Postgres.Model(&structs.Label{}).
+			Where("project_id = ?", project.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		println("Removed", results.RowsAffected(), "label entries")
+
 		issues := make([]structs.IssueEntry, 0)
 
 		err = er.Postgres.Model(&issues).
@@ -1630,6 +2985,15 @@ func APIProjectDeleteHandler(er *Errorly) http.HandlerFunc {
 		affected := 0
 
 		for _, issue := range issues {
+			_, err = er.Postgres.Model(&structs.CommentEdit{}).
+				Where("comment_id IN (SELECT id FROM comments WHERE issue_id = ?)", issue.ID).
+				Delete()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
 			results, err = er.Postgres.Model(&structs.Comment{}).
 				Where("issue_id = ?", issue.ID).
 				Delete()
@@ -1640,6 +3004,33 @@ func APIProjectDeleteHandler(er *Errorly) http.HandlerFunc {
 			}
 
 			affected += results.RowsAffected()
+
+			_, err = er.Postgres.Model(&structs.AffectedUser{}).
+				Where("issue_id = ?", issue.ID).
+				Delete()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			_, err = er.Postgres.Model(&structs.IssueSubscription{}).
+				Where("issue_id = ?", issue.ID).
+				Delete()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			_, err = er.Postgres.Model(&structs.IssueLabel{}).
+				Where("issue_id = ?", issue.ID).
+				Delete()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
 		}
 
 		println("Removed", affected, "comment entries")
@@ -1762,6 +3153,8 @@ func APIProjectTransferHandler(er *Errorly) http.HandlerFunc {
 
 		contributorIDs = append(contributorIDs, project.CreatedByID)
 
+		previousOwnerID := project.CreatedByID
+
 		project.CreatedByID = userID
 		project.Settings.ContributorIDs = contributorIDs
 
@@ -1774,24 +3167,35 @@ func APIProjectTransferHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
+		err = recordAudit(er, r, project, user, structs.AuditProjectTransfer, strconv.FormatInt(userID, 10),
+			map[string]int64{"created_by_id": previousOwnerID},
+			map[string]int64{"created_by_id": userID})
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
 		passResponse(rw, "Project owner transferred", true, http.StatusOK)
 	}
 }
 
-// APIProjectIssueHandler returns paginated results.
-func APIProjectIssueHandler(er *Errorly) http.HandlerFunc {
+// APIProjectAuditHandler returns the audit log of a project, newest first.
+// Entries can be filtered by action, actor and target.
+func APIProjectAuditHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		urlQuery := r.URL.Query()
-
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
 
-		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
 		if !ok {
 			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
 			return
@@ -1805,95 +3209,146 @@ func APIProjectIssueHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		_issueID := urlQuery.Get("id")
-		if _issueID != "" {
-			// If an ID is specified we will instead return just the issue
-			issueID, err := strconv.ParseInt(_issueID, 10, 64)
-			if err != nil {
-				passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
 
-				return
-			}
+			return
+		}
 
-			issue := &structs.IssueEntry{}
+		limit, err := parsePageLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			passResponse(rw, "Limit argument is not valid", false, http.StatusBadRequest)
 
-			err = er.Postgres.Model(issue).
-				Where("issue_entry.project_id = ?", project.ID).
-				Where("issue_entry.id = ?", issueID).
-				Select()
-			if err != nil {
-				if errors.Is(err, pg.ErrNoRows) {
-					// Invalid issue ID
-					passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+			return
+		}
 
-					return
-				}
+		entries := make([]structs.AuditLogEntry, 0, limit+1)
 
-				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+		query := er.Postgres.Model(&entries).
+			Where("project_id = ?", project.ID).
+			Order("id DESC")
+
+		if _action := r.URL.Query().Get("action"); _action != "" {
+			action, err := structs.ParseAuditAction(_action)
+			if err != nil {
+				passResponse(rw, "Action argument is not valid", false, http.StatusBadRequest)
 
 				return
 			}
 
-			passResponse(rw, structs.APIProjectIssues{
-				Issue: issue,
-			}, true, http.StatusOK)
+			query = query.Where("action = ?", action)
+		}
 
-			return
+		if _actor := r.URL.Query().Get("actor"); _actor != "" {
+			actorID, err := strconv.ParseInt(_actor, 10, 64)
+			if err != nil {
+				passResponse(rw, "Actor argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			query = query.Where("actor_id = ?", actorID)
 		}
 
-		// Get query from search
-		query := urlQuery.Get("q")
-		if query == "" {
-			query = ""
+		if target := r.URL.Query().Get("target"); target != "" {
+			query = query.Where("target = ?", target)
 		}
 
-		// We should get a limit argument here but at the moment
-		// we will hardcode 25 per page.
-		_pageLimit := pageLimit
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			values, err := decodeCursor(cursor, []string{"id"})
+			if err != nil {
+				passResponse(rw, "Cursor argument is not valid", false, http.StatusBadRequest)
 
-		// Retrieve page argument from URL
-		_page := r.FormValue("page")
-		if _page == "" {
-			passResponse(rw, "Page argument is missing", false, http.StatusBadRequest)
+				return
+			}
 
-			return
+			query = applyCursor(query, []string{"id"}, []bool{true}, values)
 		}
 
-		// Check page is a valid number. We will use the first page
-		// argument provided as multiple could be passed.
-		page, err := strconv.Atoi(_page)
+		// We will fetch an extra entry to know if there are any more pages.
+		err = query.Limit(limit + 1).Select()
 		if err != nil {
-			passResponse(rw, "Page argument is not valid", false, http.StatusBadRequest)
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		var nextCursor string
+
+		if len(entries) > limit {
+			entries = entries[:limit]
+			nextCursor = encodeCursor([]string{"id"}, []string{
+				strconv.FormatInt(entries[len(entries)-1].ID, 10),
+			})
+		}
+
+		passResponse(rw, structs.APIProjectAudit{
+			Limit:      limit,
+			NextCursor: nextCursor,
+			Entries:    entries,
+		}, true, http.StatusOK)
+	}
+}
+
+// APIProjectSearchesHandler returns the shared saved searches of a project along
+// with any saved searches the user has made.
+func APIProjectSearchesHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
 
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
 			return
 		}
 
-		var issues []structs.IssueEntry
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
 
-		var totalissues int
+			return
+		}
 
+		var userID int64
 		if auth {
-			issues, totalissues, err = fetchProjectIssues(er, project.ID, _pageLimit, page, query, user.ID)
-		} else {
-			issues, totalissues, err = fetchProjectIssues(er, project.ID, _pageLimit, page, query, 0)
+			userID = user.ID
 		}
 
+		searches := make([]structs.SavedSearch, 0)
+
+		err := er.Postgres.Model(&searches).
+			Where("project_id = ?", project.ID).
+			WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+				q = q.WhereOr("shared = ?", true).WhereOr("created_by_id = ?", userID)
+
+				return q, nil
+			}).
+			Order("name ASC").
+			Select()
 		if err != nil {
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		passResponse(rw, structs.APIProjectIssues{
-			Page:        page,
-			TotalIssues: totalissues,
-			Issues:      issues,
+		passResponse(rw, structs.APIProjectSearches{
+			Searches:        searches,
+			DefaultSearchID: project.Settings.DefaultSearchID,
 		}, true, http.StatusOK)
 	}
 }
 
-// APIProjectIssueCreateHandler creates a new issue or increments an already made issue.
-func APIProjectIssueCreateHandler(er *Errorly) http.HandlerFunc {
+// APIProjectSearchCreateHandler handles creating a saved search.
+func APIProjectSearchCreateHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
@@ -1915,19 +3370,12 @@ func APIProjectIssueCreateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		// Retrieve project and user permissions
 		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
 		if !ok {
 			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
 			return
 		}
 
-		if !elevated {
-			passResponse(rw, "You do not have permission to do this", false, http.StatusForbidden)
-
-			return
-		}
-
 		if !viewable {
 			// No permission to view project. We will treat like the project
 			// does not exist.
@@ -1936,268 +3384,2740 @@ func APIProjectIssueCreateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		if project.Settings.Archived {
-			// If the project is Archived, new issues cannot be made
-			passResponse(rw, "This project is archived", false, http.StatusForbidden)
+		searchName := strings.TrimSpace(r.FormValue("name"))
+		if len(searchName) == 0 {
+			passResponse(rw, "Invalid name was passed", false, http.StatusBadRequest)
 
 			return
 		}
 
-		// Retrieve issue error
-		issueError := r.FormValue("error")
-		if issueError == "" {
-			passResponse(rw, "Error is missing", false, http.StatusBadRequest)
+		searchQuery := strings.TrimSpace(r.FormValue("query"))
+		if _, err := splitIssueQuery(searchQuery); err != nil {
+			passResponse(rw, "Query argument is not valid", false, http.StatusBadRequest)
 
 			return
 		}
 
-		// Retrieve issue function
-		issueFunction := r.FormValue("function")
-		if issueFunction == "" {
-			passResponse(rw, "Function is missing", false, http.StatusBadRequest)
+		shared, err := strconv.ParseBool(r.FormValue("shared"))
+		if err != nil {
+			shared = false
+		}
+
+		if shared && !elevated {
+			// Only contributors can share searches with the project.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
 
 			return
 		}
 
-		now := time.Now().UTC()
-		issue := &structs.IssueEntry{}
-		newIssue := false
+		search := &structs.SavedSearch{
+			ID:        er.IDGen.GenerateID(),
+			ProjectID: project.ID,
 
-		err := er.Postgres.Model(issue).
-			Where("error = ?", issueError).
-			Where("function = ?", issueFunction).
-			Select()
+			CreatedAt:   time.Now().UTC(),
+			CreatedByID: user.ID,
+
+			Name:   searchName,
+			Query:  searchQuery,
+			Shared: shared,
+		}
+
+		_, err = er.Postgres.Model(search).Insert()
 		if err != nil {
-			if !errors.Is(err, pg.ErrNoRows) {
-				// Unexpected error
-				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, search, true, http.StatusOK)
+	}
+}
+
+// APIProjectSearchUpdateHandler handles updating a saved search. Users can
+// update their own searches and elevated users can update shared searches.
+func APIProjectSearchUpdateHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		searchID, err := strconv.ParseInt(vars["search_id"], 10, 64)
+		if err != nil {
+			passResponse(rw, "SearchID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		search, err := fetchSavedSearch(er, project.ID, searchID, user.ID)
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				passResponse(rw, "Could not find this search", false, http.StatusBadRequest)
 
 				return
 			}
 
-			assigneeID, err := strconv.ParseInt(r.FormValue("assigned"), 10, 64)
-			if err != nil {
-				assigneeID = 0
-			}
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
-			isContributor := false
-			if assigneeID == project.CreatedByID {
-				isContributor = true
-			} else {
-				for _, contributorID := range project.Settings.ContributorIDs {
-					if contributorID == assigneeID {
-						isContributor = true
-					}
-				}
-			}
+			return
+		}
 
-			if !isContributor {
-				assigneeID = 0
-			}
+		if search.CreatedByID != user.ID && !elevated {
+			passResponse(rw, "You do not have permission to do this", false, http.StatusForbidden)
 
-			commentsLocked, err := strconv.ParseBool(r.FormValue("lock_comments"))
-			if err != nil {
-				commentsLocked = false
-			}
+			return
+		}
 
-			newIssue = true
-			issue = &structs.IssueEntry{
-				ID:             er.IDGen.GenerateID(),
-				ProjectID:      project.ID,
-				Starred:        false,
-				Type:           structs.EntryOpen,
-				Occurrences:    1,
-				AssigneeID:     assigneeID,
-				Error:          issueError,
-				Function:       issueFunction,
-				Checkpoint:     r.FormValue("checkpoint"),
-				Description:    r.FormValue("description"),
-				Traceback:      r.FormValue("traceback"),
-				LastModified:   now,
-				CreatedAt:      now,
-				CreatedByID:    user.ID,
-				CommentCount:   0,
-				CommentsLocked: commentsLocked,
-			}
+		if _name := strings.TrimSpace(r.FormValue("name")); _name != "" {
+			search.Name = _name
+		}
 
-			_, err = er.Postgres.Model(issue).Insert()
-			if err != nil {
-				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+		if _query, ok := r.Form["query"]; ok {
+			searchQuery := strings.TrimSpace(_query[0])
+			if _, err := splitIssueQuery(searchQuery); err != nil {
+				passResponse(rw, "Query argument is not valid", false, http.StatusBadRequest)
 
 				return
 			}
 
-			switch issue.Type {
-			case structs.EntryActive:
-				project.ActiveIssues++
-			case structs.EntryOpen:
-				project.OpenIssues++
-			case structs.EntryResolved:
-				project.ResolvedIssues++
-			case structs.EntryInvalid:
-			}
+			search.Query = searchQuery
+		}
 
-			// Update issues cache counter on project
-			_, err = er.Postgres.Model(project).
-				WherePK().
-				Update()
-			if err != nil {
-				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+		if _shared, err := strconv.ParseBool(r.FormValue("shared")); err == nil {
+			if _shared && !elevated {
+				passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
 
 				return
 			}
 
-			err = er.HandleProjectWebhook(project, structs.WebhookMessage{
-				Type:    structs.IssueCreate,
-				Project: project,
-				Issue:   issue,
-				Author:  user,
-			})
-			if err != nil {
-				er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+			if !_shared && project.Settings.DefaultSearchID == search.ID {
+				passResponse(rw, "The default view of a project must be shared", false, http.StatusBadRequest)
+
+				return
 			}
-		} else {
-			// An error with this function and error already exists, increment it again
-			issue.Occurrences++
-			issue.LastModified = now
 
-			// We will overwrite the assignee and lock comments if the creator is the same person
-			if user.ID == issue.CreatedByID {
-				assigneeID, err := strconv.ParseInt(r.FormValue("assigned"), 10, 64)
-				if err != nil {
-					assigneeID = 0
-				}
+			search.Shared = _shared
+		}
 
-				isContributor := false
-				if assigneeID == project.CreatedByID {
-					isContributor = true
-				} else {
-					for _, contributorID := range project.Settings.ContributorIDs {
-						if contributorID == assigneeID {
-							isContributor = true
-						}
-					}
-				}
-				if isContributor {
-					issue.AssigneeID = assigneeID
-				}
+		_, err = er.Postgres.Model(search).
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
-				commentsLocked, err := strconv.ParseBool(r.FormValue("lock_comments"))
-				if err == nil {
-					issue.CommentsLocked = commentsLocked
-				}
+			return
+		}
+
+		passResponse(rw, search, true, http.StatusOK)
+	}
+}
+
+// APIProjectSearchDeleteHandler handles deleting a saved search. If the search
+// was the default view of the project, the default view is cleared.
+func APIProjectSearchDeleteHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		searchID, err := strconv.ParseInt(vars["search_id"], 10, 64)
+		if err != nil {
+			passResponse(rw, "SearchID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		search, err := fetchSavedSearch(er, project.ID, searchID, user.ID)
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				passResponse(rw, "Could not find this search", false, http.StatusBadRequest)
+
+				return
 			}
 
-			_, err = er.Postgres.Model(issue).
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if search.CreatedByID != user.ID && !elevated {
+			passResponse(rw, "You do not have permission to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(search).
+			WherePK().
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if project.Settings.DefaultSearchID == search.ID {
+			project.Settings.DefaultSearchID = 0
+
+			_, err = er.Postgres.Model(project).
 				WherePK().
 				Update()
 			if err != nil {
-				// Error updating query
 				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 				return
 			}
+		}
+
+		passResponse(rw, "OK", true, http.StatusOK)
+	}
+}
+
+// APIProjectLabelsHandler returns the labels of a project.
+func APIProjectLabelsHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		labels := make([]structs.Label, 0)
+
+		err := er.Postgres.Model(&labels).
+			Where("project_id = ?", project.ID).
+			Order("name ASC").
+			Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, structs.APIProjectLabels{
+			Labels: labels,
+		}, true, http.StatusOK)
+	}
+}
+
+// APIProjectLabelCreateHandler handles creating a label.
+func APIProjectLabelCreateHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		labelName := strings.TrimSpace(r.FormValue("name"))
+		if !isValidLabelName(labelName) {
+			passResponse(rw, "Invalid name was passed", false, http.StatusBadRequest)
+
+			return
+		}
+
+		labelColor := r.FormValue("color")
+		if !labelColorRegex.MatchString(labelColor) {
+			passResponse(rw, "Invalid color was passed", false, http.StatusBadRequest)
+
+			return
+		}
+
+		exists, err := er.Postgres.Model(&structs.Label{}).
+			Where("project_id = ?", project.ID).
+			Where("lower(name) = lower(?)", labelName).
+			Exists()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if exists {
+			passResponse(rw, "You cannot have multiple labels with the same name", false, http.StatusBadRequest)
+
+			return
+		}
+
+		label := &structs.Label{
+			ID:        er.IDGen.GenerateID(),
+			ProjectID: project.ID,
+
+			CreatedAt:   time.Now().UTC(),
+			CreatedByID: user.ID,
+
+			Name:        labelName,
+			Color:       strings.ToLower(labelColor),
+			Description: strings.TrimSpace(r.FormValue("description")),
+		}
+
+		_, err = er.Postgres.Model(label).Insert()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, label, true, http.StatusOK)
+	}
+}
+
+// APIProjectLabelUpdateHandler handles updating a label.
+func APIProjectLabelUpdateHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		labelID, err := strconv.ParseInt(vars["label_id"], 10, 64)
+		if err != nil {
+			passResponse(rw, "LabelID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		label := &structs.Label{}
+
+		err = er.Postgres.Model(label).
+			Where("project_id = ?", project.ID).
+			Where("id = ?", labelID).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				passResponse(rw, "Could not find this label", false, http.StatusBadRequest)
+
+				return
+			}
+
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if _name := strings.TrimSpace(r.FormValue("name")); _name != "" && _name != label.Name {
+			if !isValidLabelName(_name) {
+				passResponse(rw, "Invalid name was passed", false, http.StatusBadRequest)
+
+				return
+			}
+
+			exists, err := er.Postgres.Model(&structs.Label{}).
+				Where("project_id = ?", project.ID).
+				Where("id != ?", label.ID).
+				Where("lower(name) = lower(?)", _name).
+				Exists()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			if exists {
+				passResponse(rw, "You cannot have multiple labels with the same name", false, http.StatusBadRequest)
+
+				return
+			}
+
+			label.Name = _name
+		}
+
+		if _color := r.FormValue("color"); _color != "" {
+			if !labelColorRegex.MatchString(_color) {
+				passResponse(rw, "Invalid color was passed", false, http.StatusBadRequest)
+
+				return
+			}
+
+			label.Color = strings.ToLower(_color)
+		}
+
+		if _description, ok := r.Form["description"]; ok {
+			label.Description = strings.TrimSpace(_description[0])
+		}
+
+		_, err = er.Postgres.Model(label).
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, label, true, http.StatusOK)
+	}
+}
+
+// APIProjectLabelDeleteHandler handles deleting a label and removing it from any issues.
+func APIProjectLabelDeleteHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		labelID, err := strconv.ParseInt(vars["label_id"], 10, 64)
+		if err != nil {
+			passResponse(rw, "LabelID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		res, err := er.Postgres.Model(&structs.Label{}).
+			Where("project_id = ?", project.ID).
+			Where("id = ?", labelID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if res.RowsAffected() == 0 {
+			passResponse(rw, "Could not find this label", false, http.StatusBadRequest)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.IssueLabel{}).
+			Where("label_id = ?", labelID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, "OK", true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueHandler returns paginated results.
+func APIProjectIssueHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		urlQuery := r.URL.Query()
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		_issueID := urlQuery.Get("id")
+		if _issueID != "" {
+			// If an ID is specified we will instead return just the issue
+			issueID, err := strconv.ParseInt(_issueID, 10, 64)
+			if err != nil {
+				passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			issue := &structs.IssueEntry{}
+
+			err = er.Postgres.Model(issue).
+				Where("issue_entry.project_id = ?", project.ID).
+				Where("issue_entry.id = ?", issueID).
+				Relation("Labels").
+				Select()
+			if err != nil {
+				if errors.Is(err, pg.ErrNoRows) {
+					// Invalid issue ID
+					passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+
+					return
+				}
+
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			passResponse(rw, structs.APIProjectIssues{
+				Issue: issue,
+			}, true, http.StatusOK)
+
+			return
+		}
+
+		// Get query from search
+		query := urlQuery.Get("q")
+
+		var userID int64
+		if auth {
+			userID = user.ID
+		}
+
+		// If a saved search is passed, we will use its query instead. When no
+		// query has been passed at all, the project default view is used.
+		_searchID := urlQuery.Get("search")
+		if _, hasQuery := urlQuery["q"]; _searchID == "" && !hasQuery && project.Settings.DefaultSearchID != 0 {
+			_searchID = strconv.FormatInt(project.Settings.DefaultSearchID, 10)
+		}
+
+		if _searchID != "" {
+			searchID, err := strconv.ParseInt(_searchID, 10, 64)
+			if err != nil {
+				passResponse(rw, "Search argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			search, err := fetchSavedSearch(er, project.ID, searchID, userID)
+			if err != nil {
+				if errors.Is(err, pg.ErrNoRows) {
+					passResponse(rw, "Could not find this search", false, http.StatusBadRequest)
+
+					return
+				}
+
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			query = search.Query
+		}
+
+		_pageLimit, err := parsePageLimit(urlQuery.Get("limit"))
+		if err != nil {
+			passResponse(rw, "Limit argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Cursors are preferred over pages as they will not skip or repeat
+		// issues when new issues are made.
+		cursor := urlQuery.Get("cursor")
+
+		// Retrieve page argument from URL
+		_page := r.FormValue("page")
+		if _page == "" {
+			if cursor == "" {
+				passResponse(rw, "Page argument is missing", false, http.StatusBadRequest)
+
+				return
+			}
+
+			_page = "0"
+		}
+
+		// Check page is a valid number. We will use the first page
+		// argument provided as multiple could be passed.
+		page, err := strconv.Atoi(_page)
+		if err != nil {
+			passResponse(rw, "Page argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issues, totalissues, nextCursor, err := fetchProjectIssues(er, project.ID, _pageLimit, page, cursor, query, userID)
+		if err != nil {
+			if errors.Is(err, ErrInvalidCursor) {
+				passResponse(rw, "Cursor argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		// Facets are included by default however they can be skipped
+		// as they require an extra query per facet.
+		var facets *structs.APIProjectIssueFacets
+
+		if includeFacets, err := strconv.ParseBool(urlQuery.Get("facets")); err != nil || includeFacets {
+			facets, err = fetchProjectIssueFacets(er, project.ID, query, userID)
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+		}
+
+		passResponse(rw, structs.APIProjectIssues{
+			Page:        page,
+			Limit:       _pageLimit,
+			NextCursor:  nextCursor,
+			Query:       query,
+			TotalIssues: totalissues,
+			Issues:      issues,
+			Facets:      facets,
+		}, true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueCreateHandler creates a new issue or increments an already made issue.
+func APIProjectIssueCreateHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		// Retrieve project and user permissions
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !elevated {
+			passResponse(rw, "You do not have permission to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if project.Settings.Archived {
+			// If the project is Archived, new issues cannot be made
+			passResponse(rw, "This project is archived", false, http.StatusForbidden)
+
+			return
+		}
+
+		// Retrieve issue error
+		issueError := r.FormValue("error")
+		if issueError == "" {
+			passResponse(rw, "Error is missing", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Retrieve issue function
+		issueFunction := r.FormValue("function")
+		if issueFunction == "" {
+			passResponse(rw, "Function is missing", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Identifier of the user who encountered the issue, this is
+		// used to count how many users an issue affects.
+		affectedUser := strings.TrimSpace(r.FormValue("user"))
+
+		release := strings.TrimSpace(r.FormValue("release"))
+		if release != "" {
+			err := recordRelease(er, project.ID, release)
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+		}
+
+		now := time.Now().UTC()
+		issue := &structs.IssueEntry{}
+		newIssue := false
+		regressed := false
+
+		err := er.Postgres.Model(issue).
+			Where("project_id = ?", project.ID).
+			Where("error = ?", issueError).
+			Where("function = ?", issueFunction).
+			Select()
+		if err != nil {
+			if !errors.Is(err, pg.ErrNoRows) {
+				// Unexpected error
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			assigneeID, err := strconv.ParseInt(r.FormValue("assigned"), 10, 64)
+			if err != nil {
+				assigneeID = 0
+			}
+
+			isContributor := false
+			if assigneeID == project.CreatedByID {
+				isContributor = true
+			} else {
+				for _, contributorID := range project.Settings.ContributorIDs {
+					if contributorID == assigneeID {
+						isContributor = true
+					}
+				}
+			}
+
+			if !isContributor {
+				assigneeID = 0
+			}
+
+			commentsLocked, err := strconv.ParseBool(r.FormValue("lock_comments"))
+			if err != nil {
+				commentsLocked = false
+			}
+
+			// An explicit priority takes precedence over the project's
+			// priority rules.
+			priority, err := structs.ParsePriority(r.FormValue("priority"))
+			if err != nil {
+				priority = matchPriorityRules(project.Settings.PriorityRules, issueError)
+			}
+
+			newIssue = true
+			issue = &structs.IssueEntry{
+				ID:             er.IDGen.GenerateID(),
+				ProjectID:      project.ID,
+				Starred:        false,
+				Type:           structs.EntryOpen,
+				Priority:       priority,
+				Occurrences:    1,
+				AssigneeID:     assigneeID,
+				Error:          issueError,
+				Function:       issueFunction,
+				Checkpoint:     r.FormValue("checkpoint"),
+				Description:    r.FormValue("description"),
+				Traceback:      r.FormValue("traceback"),
+				Environment:    r.FormValue("environment"),
+				Release:        release,
+				LastModified:   now,
+				LastSeen:       now,
+				CreatedAt:      now,
+				CreatedByID:    user.ID,
+				CommentCount:   0,
+				CommentsLocked: commentsLocked,
+			}
+
+			if affectedUser != "" {
+				issue.UsersAffected = 1
+			}
+
+			_, err = er.Postgres.Model(issue).Insert()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			if affectedUser != "" {
+				_, err = recordAffectedUser(er, issue.ID, affectedUser)
+				if err != nil {
+					passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+					return
+				}
+			}
+
+			err = er.Subscribe(issue, user)
+			if err != nil {
+				er.Logger.Warn().Err(err).Msg("Failed to subscribe creator")
+			}
+
+			if issue.AssigneeID != 0 {
+				err = er.Subscribe(issue, &structs.User{ID: issue.AssigneeID})
+				if err != nil {
+					er.Logger.Warn().Err(err).Msg("Failed to subscribe assignee")
+				}
+			}
+
+			switch issue.Type {
+			case structs.EntryActive:
+				project.ActiveIssues++
+			case structs.EntryOpen:
+				project.OpenIssues++
+			case structs.EntryResolved:
+				project.ResolvedIssues++
+			case structs.EntryInvalid:
+			}
+
+			// Update issues cache counter on project
+			_, err = er.Postgres.Model(project).
+				WherePK().
+				Update()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			err = er.HandleProjectWebhook(project, structs.WebhookMessage{
+				Type:    structs.IssueCreate,
+				Project: project,
+				Issue:   issue,
+				Author:  user,
+			})
+			if err != nil {
+				er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+			}
+		} else {
+			// An error with this function and error already exists, increment it again
+			issue.Occurrences++
+			issue.LastModified = now
+			issue.LastSeen = now
+
+			if _environment := r.FormValue("environment"); _environment != "" {
+				issue.Environment = _environment
+			}
+
+			if affectedUser != "" {
+				newUser, err := recordAffectedUser(er, issue.ID, affectedUser)
+				if err != nil {
+					passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+					return
+				}
+
+				if newUser {
+					issue.UsersAffected++
+				}
+			}
+
+			// We will overwrite the assignee and lock comments if the creator is the same person
+			if user.ID == issue.CreatedByID {
+				assigneeID, err := strconv.ParseInt(r.FormValue("assigned"), 10, 64)
+				if err != nil {
+					assigneeID = 0
+				}
+
+				isContributor := false
+				if assigneeID == project.CreatedByID {
+					isContributor = true
+				} else {
+					for _, contributorID := range project.Settings.ContributorIDs {
+						if contributorID == assigneeID {
+							isContributor = true
+						}
+					}
+				}
+				if isContributor {
+					issue.AssigneeID = assigneeID
+				}
+
+				commentsLocked, err := strconv.ParseBool(r.FormValue("lock_comments"))
+				if err == nil {
+					issue.CommentsLocked = commentsLocked
+				}
+			}
+
+			// If a resolved issue happens again, it has regressed and
+			// will be reopened. Issues resolved in a release will
+			// silently absorb occurrences from older releases. Snoozed
+			// issues absorb occurrences until WakeSnoozedIssues wakes them.
+			absorbed := issue.Type == structs.EntrySnoozed
+
+			if issue.Type == structs.EntryResolved {
+				regressed, err = isRegression(er, project.ID, issue, release)
+				if err != nil {
+					passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+					return
+				}
+
+				absorbed = !regressed
+			}
+
+			// Occurrences absorbed by a resolved issue are from older
+			// releases so do not change the release it was last seen in.
+			if release != "" && (issue.Type != structs.EntryResolved || regressed) {
+				issue.Release = release
+			}
+
+			if regressed {
+				err = er.MarkIssueStatus(project, issue, structs.EntryActive, systemUser)
+			} else {
+				_, err = er.Postgres.Model(issue).
+					WherePK().
+					Update()
+			}
+
+			if err != nil {
+				// Error updating query
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			if !absorbed {
+				err = er.HandleProjectWebhook(project, structs.WebhookMessage{
+					Type:    structs.IssueCreate,
+					Project: project,
+					Issue:   issue,
+					Author:  user,
+				})
+				if err != nil {
+					er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+				}
+			}
+		}
+
+		issue.RenderMarkdown()
+
+		passResponse(rw, structs.APIProjectIssueCreate{
+			New:       newIssue,
+			Regressed: regressed,
+			Issue:     issue,
+		}, true, http.StatusOK)
+	}
+}
+
+// APIProjectFetchIssueHandler returns an issue from a project.
+func APIProjectFetchIssueHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		_issueID, ok := vars["issue_id"]
+		if !ok {
+			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// If an ID is specified we will instead return just the issue
+		issueID, err := strconv.ParseInt(_issueID, 10, 64)
+		if err != nil {
+			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue := &structs.IssueEntry{}
+
+		err = er.Postgres.Model(issue).
+			Where("issue_entry.project_id = ?", project.ID).
+			Where("issue_entry.id = ?", issueID).
+			Relation("Labels").
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				// Invalid issue ID
+				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+
+				return
+			}
+
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, structs.APIProjectIssues{
+			Issue: issue,
+		}, true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueDeleteHandler handles deleting an issue.
+func APIProjectIssueDeleteHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		_issueID, ok := vars["issue_id"]
+		if !ok {
+			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// If an ID is specified we will instead return just the issue
+		issueID, err := strconv.ParseInt(_issueID, 10, 64)
+		if err != nil {
+			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue := &structs.IssueEntry{}
+
+		err = er.Postgres.Model(issue).
+			Where("issue_entry.project_id = ?", project.ID).
+			Where("issue_entry.id = ?", issueID).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				// Invalid issue ID
+				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+
+				return
+			}
+
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if !elevated || issue.CreatedByID != user.ID {
+			passResponse(rw, "You must be elevated or the issue creator to delete it", false, http.StatusForbidden)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(issue).
+			WherePK().
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		switch issue.Type {
+		case structs.EntryActive:
+			project.ActiveIssues--
+		case structs.EntryOpen:
+			project.OpenIssues--
+		case structs.EntryResolved:
+			project.ResolvedIssues--
+		case structs.EntryInvalid:
+		}
+
+		// Update issues cache counter on project
+		_, err = er.Postgres.Model(project).
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.CommentEdit{}).
+			Where("comment_id IN (SELECT id FROM comments WHERE issue_id = ?)", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.Notification{}).
+			Where("issue_id = ?", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.IssueSubscription{}).
+			Where("issue_id = ?", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.Reaction{}).
+			Where("issue_id = ?", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.Activity{}).
+			Where("issue_id = ?", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.Comment{}).
+			Where("issue_id = ?", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.AffectedUser{}).
+			Where("issue_id = ?", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.IssueLabel{}).
+			Where("issue_id = ?", issue.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, "Issue was deleted", true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueCommentHandler returns a list of comments from an issue and returns paginated results.
+func APIProjectIssueCommentHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		_issueID, ok := vars["issue_id"]
+		if !ok {
+			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// If an ID is specified we will instead return just the issue
+		issueID, err := strconv.ParseInt(_issueID, 10, 64)
+		if err != nil {
+			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue := &structs.IssueEntry{}
+
+		err = er.Postgres.Model(issue).
+			Where("issue_entry.project_id = ?", project.ID).
+			Where("issue_entry.id = ?", issueID).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				// Invalid issue ID
+				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+
+				return
+			}
+
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		// We will use the same page limit for the issues per query limit
+		_issueLimit, err := parsePageLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			passResponse(rw, "Limit argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Retrieve page argument from URL
+		_page := r.URL.Query().Get("page")
+		if _page == "" {
+			_page = "0"
+		}
+
+		// Check page is a valid number. We will use the first page
+		// argument provided as multiple could be passed.
+		page, err := strconv.Atoi(_page)
+		if err != nil {
+			passResponse(rw, "Page argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		comments := make([]structs.Comment, 0, _issueLimit+1)
+
+		query := er.Postgres.Model(&comments).
+			Relation("Edits", func(q *orm.Query) (*orm.Query, error) {
+				return q.Order("comment_edit.id ASC"), nil
+			}).
+			Where("comment.issue_id = ?", issue.ID).
+			Order("comment.id ASC")
+
+		// Comments are ordered by their snowflake so the cursor only
+		// needs to contain the last comment ID.
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			values, err := decodeCursor(cursor, []string{"id"})
+			if err != nil {
+				passResponse(rw, "Cursor argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			query = applyCursor(query, []string{"id"}, []bool{false}, values)
+		} else {
+			query = query.Offset(int(math.Max(0, float64(_issueLimit*page))))
+		}
+
+		// We will fetch an extra comment to know if there are any more pages.
+		err = query.Limit(_issueLimit + 1).Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		var nextCursor string
+
+		if len(comments) > _issueLimit {
+			comments = comments[:_issueLimit]
+			nextCursor = encodeCursor([]string{"id"}, []string{
+				strconv.FormatInt(comments[len(comments)-1].ID, 10),
+			})
+		}
+
+		passResponse(rw, structs.APIProjectIssueComments{
+			Page:       page,
+			Limit:      _issueLimit,
+			NextCursor: nextCursor,
+			Comments:   comments,
+			End:        nextCursor == "",
+		}, true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueTimelineHandler returns the comments and activity of an
+// issue interleaved in the order they happened.
+func APIProjectIssueTimelineHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue, ok := fetchViewableIssue(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		limit, err := parsePageLimit(r.URL.Query().Get("limit"))
+		if err != nil {
+			passResponse(rw, "Limit argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Comments and activity both use snowflakes so the cursor only
+		// needs to contain the ID of the last entry.
+		var after int64
+
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			values, err := decodeCursor(cursor, []string{"id"})
+			if err != nil {
+				passResponse(rw, "Cursor argument is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			after = values[0].(int64)
+		}
+
+		// We will fetch an extra entry of each to know if there are any more pages.
+		comments := make([]*structs.Comment, 0, limit+1)
+
+		err = er.Postgres.Model(&comments).
+			Relation("Edits", func(q *orm.Query) (*orm.Query, error) {
+				return q.Order("comment_edit.id ASC"), nil
+			}).
+			Where("comment.issue_id = ?", issue.ID).
+			Where("comment.id > ?", after).
+			Order("comment.id ASC").
+			Limit(limit + 1).
+			Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		activities := make([]*structs.Activity, 0, limit+1)
+
+		err = er.Postgres.Model(&activities).
+			Where("issue_id = ?", issue.ID).
+			Where("action NOT IN (?)", pg.In(commentedActions)).
+			Where("id > ?", after).
+			Order("id ASC").
+			Limit(limit + 1).
+			Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		timeline := make([]structs.TimelineEntry, 0, len(comments)+len(activities))
+
+		for len(comments) > 0 || len(activities) > 0 {
+			if len(activities) == 0 || (len(comments) > 0 && comments[0].ID < activities[0].ID) {
+				timeline = append(timeline, structs.TimelineEntry{
+					ID:        comments[0].ID,
+					CreatedAt: comments[0].CreatedAt,
+					Comment:   comments[0],
+				})
+				comments = comments[1:]
+			} else {
+				timeline = append(timeline, structs.TimelineEntry{
+					ID:        activities[0].ID,
+					CreatedAt: activities[0].CreatedAt,
+					Activity:  activities[0],
+				})
+				activities = activities[1:]
+			}
+		}
+
+		var nextCursor string
+
+		if len(timeline) > limit {
+			timeline = timeline[:limit]
+			nextCursor = encodeCursor([]string{"id"}, []string{
+				strconv.FormatInt(timeline[len(timeline)-1].ID, 10),
+			})
+		}
+
+		passResponse(rw, structs.APIProjectIssueTimeline{
+			Limit:      limit,
+			NextCursor: nextCursor,
+			Timeline:   timeline,
+			End:        nextCursor == "",
+		}, true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueCommentCreateHandler handles the creation of issue comments.
+func APIProjectIssueCommentCreateHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		_issueID, ok := vars["issue_id"]
+		if !ok {
+			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if project.Settings.Limited && !elevated {
+			passResponse(rw, "You need to be a contributor of this project to create issues", false, http.StatusForbidden)
+
+			return
+		}
+
+		if project.Settings.Archived {
+			passResponse(rw, "This project is archived", false, http.StatusForbidden)
+
+			return
+		}
+
+		// If an ID is specified we will instead return just the issue
+		issueID, err := strconv.ParseInt(_issueID, 10, 64)
+		if err != nil {
+			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue := &structs.IssueEntry{}
+
+		err = er.Postgres.Model(issue).
+			Where("issue_entry.project_id = ?", project.ID).
+			Where("issue_entry.id = ?", issueID).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				// Invalid issue ID
+				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+
+				return
+			}
+
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if issue.CommentsLocked {
+			passResponse(rw, "Comments are locked for this issue", false, http.StatusForbidden)
+
+			return
+		}
+
+		content := strings.TrimSpace(r.PostFormValue("content"))
+		if len(content) == 0 {
+			passResponse(rw, "Invalid message content was passed", false, http.StatusBadRequest)
+
+			return
+		}
+
+		now := time.Now().UTC()
+		comment := &structs.Comment{
+			ID:          er.IDGen.GenerateID(),
+			IssueID:     issue.ID,
+			CreatedAt:   now,
+			CreatedByID: user.ID,
+			Type:        structs.Message,
+			Content:     &content,
+		}
+		comment.RenderMarkdown()
+
+		_, err = er.Postgres.Model(comment).Insert()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		issue.CommentCount++
+		issue.LastModified = now
+
+		_, err = er.Postgres.Model(issue).
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = er.Subscribe(issue, user)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to subscribe commenter")
+		}
+
+		mentioned, err := er.NotifyMentions(project, issue, comment, user)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to notify mentions")
+		}
+
+		// Mentioned users have already been notified of this comment.
+		err = er.NotifySubscribers(project, issue, structs.IssueComment, comment, user, mentioned...)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to notify subscribers")
+		}
+
+		err = er.HandleProjectWebhook(project, structs.WebhookMessage{
+			Type:    structs.IssueComment,
+			Project: project,
+			Issue:   issue,
+			Author:  user,
+			Comment: comment,
+		})
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+		}
+
+		passResponse(rw, comment, true, http.StatusOK)
+	}
+}
+
+// fetchIssueComment retrieves a comment on an issue of a project. Returns
+// false if the issue or comment could not be found, in which case an error
+// has already been provided to the ResponseWriter.
+func fetchIssueComment(er *Errorly, rw http.ResponseWriter, vars map[string]string,
+	project *structs.Project) (issue *structs.IssueEntry, comment *structs.Comment, ok bool) {
+	commentID, err := strconv.ParseInt(vars["comment_id"], 10, 64)
+	if err != nil {
+		passResponse(rw, "CommentID argument is not valid", false, http.StatusBadRequest)
+
+		return nil, nil, false
+	}
+
+	issue, ok = fetchViewableIssue(er, rw, vars, project)
+	if !ok {
+		return nil, nil, false
+	}
+
+	comment = &structs.Comment{}
+
+	err = er.Postgres.Model(comment).
+		Where("issue_id = ?", issue.ID).
+		Where("id = ?", commentID).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			passResponse(rw, "Could not find this comment", false, http.StatusBadRequest)
+
+			return nil, nil, false
+		}
+
+		passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+		return nil, nil, false
+	}
+
+	return issue, comment, true
+}
+
+// APIProjectIssueCommentUpdateHandler handles editing an issue comment.
+// Only the author of a message can edit it.
+func APIProjectIssueCommentUpdateHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if project.Settings.Archived {
+			passResponse(rw, "This project is archived", false, http.StatusForbidden)
+
+			return
+		}
+
+		issue, comment, ok := fetchIssueComment(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if comment.Type != structs.Message || comment.Content == nil {
+			passResponse(rw, "Only messages can be edited", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if comment.CreatedByID != user.ID {
+			passResponse(rw, "You can only edit your own comments", false, http.StatusForbidden)
+
+			return
+		}
+
+		if issue.CommentsLocked {
+			passResponse(rw, "Comments are locked for this issue", false, http.StatusForbidden)
+
+			return
+		}
+
+		content := strings.TrimSpace(r.PostFormValue("content"))
+		if len(content) == 0 {
+			passResponse(rw, "Invalid message content was passed", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if content == *comment.Content {
+			passResponse(rw, comment, true, http.StatusOK)
+
+			return
+		}
+
+		now := time.Now().UTC()
+
+		// Keep the previous content of the comment as part of its history.
+		edit := &structs.CommentEdit{
+			ID:         er.IDGen.GenerateID(),
+			CommentID:  comment.ID,
+			EditedAt:   now,
+			EditedByID: user.ID,
+			Content:    *comment.Content,
+		}
+
+		_, err := er.Postgres.Model(edit).Insert()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		comment.Content = &content
+		comment.EditedAt = &now
+		comment.RenderMarkdown()
+
+		_, err = er.Postgres.Model(comment).
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = er.Postgres.Model(&comment.Edits).
+			Where("comment_id = ?", comment.ID).
+			Order("id ASC").
+			Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = er.HandleProjectWebhook(project, structs.WebhookMessage{
+			Type:    structs.IssueCommentEdited,
+			Project: project,
+			Issue:   issue,
+			Author:  user,
+			Comment: comment,
+		})
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+		}
+
+		passResponse(rw, comment, true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueCommentDeleteHandler handles deleting an issue comment.
+// Elevated users can delete any comment and authors can delete their own
+// messages.
+func APIProjectIssueCommentDeleteHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if project.Settings.Archived {
+			passResponse(rw, "This project is archived", false, http.StatusForbidden)
+
+			return
+		}
+
+		issue, comment, ok := fetchIssueComment(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !elevated && (comment.Type != structs.Message || comment.CreatedByID != user.ID) {
+			passResponse(rw, "You can only delete your own comments", false, http.StatusForbidden)
+
+			return
+		}
+
+		_, err := er.Postgres.Model(&structs.CommentEdit{}).
+			Where("comment_id = ?", comment.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.Reaction{}).
+			Where("comment_id = ?", comment.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(comment).
+			WherePK().
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if issue.CommentCount > 0 {
+			issue.CommentCount--
+		}
+
+		issue.LastModified = time.Now().UTC()
+
+		_, err = er.Postgres.Model(issue).
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = er.HandleProjectWebhook(project, structs.WebhookMessage{
+			Type:    structs.IssueCommentDeleted,
+			Project: project,
+			Issue:   issue,
+			Author:  user,
+			Comment: comment,
+		})
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+		}
+
+		passResponse(rw, comment, true, http.StatusOK)
+	}
+}
+
+// fetchViewableIssue retrieves an issue of a project from the issue_id
+// route variable. Returns false if the issue could not be found, in which
+// case an error has already been provided to the ResponseWriter.
+func fetchViewableIssue(er *Errorly, rw http.ResponseWriter, vars map[string]string,
+	project *structs.Project) (issue *structs.IssueEntry, ok bool) {
+	issueID, err := strconv.ParseInt(vars["issue_id"], 10, 64)
+	if err != nil {
+		passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	issue = &structs.IssueEntry{}
+
+	err = er.Postgres.Model(issue).
+		Where("issue_entry.project_id = ?", project.ID).
+		Where("issue_entry.id = ?", issueID).
+		Select()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+
+			return nil, false
+		}
+
+		passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+		return nil, false
+	}
+
+	return issue, true
+}
+
+// APIProjectIssueSubscribersHandler returns the users subscribed to an issue.
+func APIProjectIssueSubscribersHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue, ok := fetchViewableIssue(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		subscriptions := make([]structs.IssueSubscription, 0)
+
+		err := er.Postgres.Model(&subscriptions).
+			Where("issue_id = ?", issue.ID).
+			Order("created_at ASC").
+			Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		subscribers := structs.APIProjectIssueSubscribers{
+			SubscriberIDs: make([]int64, 0, len(subscriptions)),
+		}
+
+		for _, subscription := range subscriptions {
+			subscribers.SubscriberIDs = append(subscribers.SubscriberIDs, subscription.UserID)
+
+			if auth && subscription.UserID == user.ID {
+				subscribers.Subscribed = true
+			}
+		}
+
+		passResponse(rw, subscribers, true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueSubscribeHandler subscribes the user to an issue.
+func APIProjectIssueSubscribeHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue, ok := fetchViewableIssue(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		err := er.Subscribe(issue, user)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, nil, true, http.StatusOK)
+	}
+}
+
+// APIProjectIssueUnsubscribeHandler unsubscribes the user from an issue.
+func APIProjectIssueUnsubscribeHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue, ok := fetchViewableIssue(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		_, err := er.Postgres.Model(&structs.IssueSubscription{}).
+			Where("issue_id = ?", issue.ID).
+			Where("user_id = ?", user.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, nil, true, http.StatusOK)
+	}
+}
+
+// fetchReactionTarget retrieves the issue and, if a comment_id route
+// variable is passed, the comment being reacted to. Returns false if they
+// could not be found, in which case an error has already been provided to
+// the ResponseWriter.
+func fetchReactionTarget(er *Errorly, rw http.ResponseWriter, vars map[string]string,
+	project *structs.Project) (issue *structs.IssueEntry, comment *structs.Comment, ok bool) {
+	if _, ok := vars["comment_id"]; ok {
+		return fetchIssueComment(er, rw, vars, project)
+	}
+
+	issue, ok = fetchViewableIssue(er, rw, vars, project)
+
+	return issue, nil, ok
+}
+
+// updateReactionCounts recounts the reactions of an issue or comment and
+// stores them.
+func updateReactionCounts(er *Errorly, issue *structs.IssueEntry, comment *structs.Comment) (err error) {
+	var commentID int64
+	if comment != nil {
+		commentID = comment.ID
+	}
+
+	var counts []struct {
+		Emoji string
+		Count int
+	}
+
+	err = er.Postgres.Model((*structs.Reaction)(nil)).
+		Column("emoji").
+		ColumnExpr("count(*) AS count").
+		Where("issue_id = ?", issue.ID).
+		Where("comment_id = ?", commentID).
+		Group("emoji").
+		Select(&counts)
+	if err != nil {
+		return xerrors.Errorf("Failed to count reactions: %w", err)
+	}
+
+	reactions := make(map[string]int)
+	total := 0
+
+	for _, count := range counts {
+		reactions[count.Emoji] = count.Count
+		total += count.Count
+	}
+
+	if comment != nil {
+		comment.Reactions = reactions
+
+		_, err = er.Postgres.Model(comment).
+			Column("reactions").
+			WherePK().
+			Update()
+	} else {
+		issue.Reactions = reactions
+		issue.ReactionCount = total
+
+		_, err = er.Postgres.Model(issue).
+			Column("reactions", "reaction_count").
+			WherePK().
+			Update()
+	}
+
+	if err != nil {
+		return xerrors.Errorf("Failed to update reactions: %w", err)
+	}
+
+	return nil
+}
+
+// APIProjectReactionsHandler returns the users who reacted to an issue or
+// comment along with partial user objects.
+func APIProjectReactionsHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue, comment, ok := fetchReactionTarget(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		var commentID int64
+		if comment != nil {
+			commentID = comment.ID
+		}
+
+		reactions := make([]structs.Reaction, 0)
+
+		err := er.Postgres.Model(&reactions).
+			Where("issue_id = ?", issue.ID).
+			Where("comment_id = ?", commentID).
+			Order("id ASC").
+			Select()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		response := structs.APIProjectReactions{
+			Reactions: make(map[string][]int64),
+			Users:     make(map[int64]structs.PartialUser),
+		}
+
+		userIDs := make([]int64, 0, len(reactions))
+
+		for _, reaction := range reactions {
+			response.Reactions[reaction.Emoji] = append(response.Reactions[reaction.Emoji], reaction.UserID)
+			userIDs = append(userIDs, reaction.UserID)
+		}
+
+		if len(userIDs) > 0 {
+			users := make([]structs.User, 0, len(userIDs))
+
+			err = er.Postgres.Model(&users).
+				WhereIn("id IN (?)", userIDs).
+				Select()
+			if err != nil {
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			for _, user := range users {
+				response.Users[user.ID] = structs.PartialUser{
+					ID:          user.ID,
+					Name:        user.Name,
+					Avatar:      user.Avatar,
+					Integration: user.Integration,
+				}
+			}
+		}
+
+		passResponse(rw, response, true, http.StatusOK)
+	}
+}
+
+// APIProjectReactionAddHandler adds a reaction to an issue or comment.
+func APIProjectReactionAddHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if project.Settings.Archived {
+			passResponse(rw, "This project is archived", false, http.StatusForbidden)
+
+			return
+		}
+
+		emoji := vars["emoji"]
+		if !structs.IsValidReaction(emoji) {
+			passResponse(rw, "Emoji argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue, comment, ok := fetchReactionTarget(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if issue.CommentsLocked && !elevated {
+			passResponse(rw, "Comments are locked for this issue", false, http.StatusForbidden)
+
+			return
+		}
+
+		reaction := &structs.Reaction{
+			ID:        er.IDGen.GenerateID(),
+			ProjectID: project.ID,
+			IssueID:   issue.ID,
+			UserID:    user.ID,
+			Emoji:     emoji,
+			CreatedAt: time.Now().UTC(),
+		}
+
+		if comment != nil {
+			reaction.CommentID = comment.ID
+		}
+
+		_, err := er.Postgres.Model(reaction).
+			OnConflict("DO NOTHING").
+			Insert()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = updateReactionCounts(er, issue, comment)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if comment != nil {
+			passResponse(rw, comment.Reactions, true, http.StatusOK)
+		} else {
+			passResponse(rw, issue.Reactions, true, http.StatusOK)
+		}
+	}
+}
+
+// APIProjectReactionRemoveHandler removes the users reaction from an
+// issue or comment.
+func APIProjectReactionRemoveHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+
+			return
+		}
+
+		if project.Settings.Archived {
+			passResponse(rw, "This project is archived", false, http.StatusForbidden)
+
+			return
+		}
+
+		emoji := vars["emoji"]
+		if !structs.IsValidReaction(emoji) {
+			passResponse(rw, "Emoji argument is not valid", false, http.StatusBadRequest)
+
+			return
+		}
+
+		issue, comment, ok := fetchReactionTarget(er, rw, vars, project)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		var commentID int64
+		if comment != nil {
+			commentID = comment.ID
+		}
+
+		_, err := er.Postgres.Model(&structs.Reaction{}).
+			Where("issue_id = ?", issue.ID).
+			Where("comment_id = ?", commentID).
+			Where("user_id = ?", user.ID).
+			Where("emoji = ?", emoji).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = updateReactionCounts(er, issue, comment)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		if comment != nil {
+			passResponse(rw, comment.Reactions, true, http.StatusOK)
+		} else {
+			passResponse(rw, issue.Reactions, true, http.StatusOK)
+		}
+	}
+}
+
+// APIProjectInviteGetHandler handles retrieving an invite.
+func APIProjectInviteGetHandler(er *Errorly) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		session, _ := er.Store.Get(r, sessionName)
+		defer er.SaveSession(session, r, rw)
+
+		vars := mux.Vars(r)
+
+		inviteCode, ok := vars["join_code"]
+		if !ok {
+			passResponse(rw, "No invite code supplied", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		project, _, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		if !ok {
+			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+			return
+		}
+
+		invite := &structs.InviteCode{}
+
+		err := er.Postgres.Model(invite).
+			Where("code = ?", inviteCode).
+			Where("project_id = ?", project.ID).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
+
+				return
+			}
 
-			err = er.HandleProjectWebhook(project, structs.WebhookMessage{
-				Type:    structs.IssueCreate,
-				Project: project,
-				Issue:   issue,
-				Author:  user,
-			})
-			if err != nil {
-				er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
-			}
+			// Unexpected error
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
 		}
 
-		passResponse(rw, structs.APIProjectIssueCreate{
-			New:   newIssue,
-			Issue: issue,
+		passResponse(rw, structs.APIProjectInviteGet{
+			ValidInvite: true,
+			ProjectName: project.Settings.DisplayName,
 		}, true, http.StatusOK)
 	}
 }
 
-// APIProjectFetchIssueHandler returns an issue from a project.
-func APIProjectFetchIssueHandler(er *Errorly) http.HandlerFunc {
+// APIProjectInviteUseHandler handles a user joining an invite.
+func APIProjectInviteUseHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		_issueID, ok := vars["issue_id"]
+		inviteCode, ok := vars["join_code"]
 		if !ok {
-			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
+			passResponse(rw, "No invite code supplied", false, http.StatusBadRequest)
 
 			return
 		}
 
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
 
-		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+			return
+		}
+
+		project, _, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
 		if !ok {
 			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
 			return
 		}
 
-		if !viewable {
-			// No permission to view project. We will treat like the project
-			// does not exist.
-			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+		if project.CreatedByID == user.ID {
+			passResponse(rw, "You are already in this project", false, http.StatusBadRequest)
 
 			return
 		}
 
-		// If an ID is specified we will instead return just the issue
-		issueID, err := strconv.ParseInt(_issueID, 10, 64)
-		if err != nil {
-			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+		for _, contributor := range project.Settings.ContributorIDs {
+			if contributor == user.ID {
+				passResponse(rw, "You are already in this project", false, http.StatusBadRequest)
 
-			return
+				return
+			}
 		}
 
-		issue := &structs.IssueEntry{}
+		invite := &structs.InviteCode{}
 
-		err = er.Postgres.Model(issue).
-			Where("issue_entry.project_id = ?", project.ID).
-			Where("issue_entry.id = ?", issueID).
+		err := er.Postgres.Model(invite).
+			Where("project_id = ?", project.ID).
+			Where("code = ?", inviteCode).
 			Select()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				// Invalid issue ID
-				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+				passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
 
 				return
 			}
 
+			// Unexpected error
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		passResponse(rw, structs.APIProjectIssues{
-			Issue: issue,
-		}, true, http.StatusOK)
+		// Check if the invite has enough uses or has expired, if so delete it.
+		if (invite.MaxUses > 0 && invite.Uses >= invite.MaxUses) ||
+			(!invite.ExpiresBy.IsZero() && time.Now().After(invite.ExpiresBy)) {
+			_, err = er.Postgres.Model(invite).
+				WherePK().
+				Delete()
+			if err != nil && !errors.Is(err, pg.ErrNoRows) {
+				// Unexpected error
+				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+				return
+			}
+
+			passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
+
+			return
+		}
+
+		// Increment invite uses
+		invite.Uses++
+
+		// Add user to contributors
+		project.Settings.ContributorIDs = append(project.Settings.ContributorIDs, user.ID)
+
+		// Add project to users project list
+		user.ProjectIDs = append(user.ProjectIDs, project.ID)
+
+		_, err = er.Postgres.Model(project).WherePK().Update()
+		if err != nil && !errors.Is(err, pg.ErrNoRows) {
+			// Unexpected error
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(invite).WherePK().Update()
+		if err != nil && !errors.Is(err, pg.ErrNoRows) {
+			// Unexpected error
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(user).WherePK().Update()
+		if err != nil && !errors.Is(err, pg.ErrNoRows) {
+			// Unexpected error
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = recordAudit(er, r, project, user, structs.AuditInviteUse, invite.Code,
+			nil, map[string]int64{"contributor_id": user.ID, "uses": invite.Uses})
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
+		passResponse(rw, "OK", true, http.StatusOK)
 	}
 }
 
-// APIProjectIssueDeleteHandler handles deleting an issue.
-func APIProjectIssueDeleteHandler(er *Errorly) http.HandlerFunc {
+// APIProjectInviteDeleteHandler handles deleting an invite.
+func APIProjectInviteDeleteHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		_issueID, ok := vars["issue_id"]
+		inviteCode, ok := vars["join_code"]
 		if !ok {
-			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
+			passResponse(rw, "No invite code supplied", false, http.StatusBadRequest)
 
 			return
 		}
 
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+
+			return
+		}
 
 		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
 		if !ok {
@@ -2213,100 +6133,60 @@ func APIProjectIssueDeleteHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		// If an ID is specified we will instead return just the issue
-		issueID, err := strconv.ParseInt(_issueID, 10, 64)
-		if err != nil {
-			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
 
 			return
 		}
 
-		issue := &structs.IssueEntry{}
+		invite := &structs.InviteCode{}
 
-		err = er.Postgres.Model(issue).
-			Where("issue_entry.project_id = ?", project.ID).
-			Where("issue_entry.id = ?", issueID).
-			Select()
+		res, err := er.Postgres.Model(invite).
+			Where("code = ?", inviteCode).
+			Where("project_id = ?", project.ID).
+			Delete()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				// Invalid issue ID
-				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+				passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
 
 				return
 			}
 
+			// Unexpected error
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		if !elevated || issue.CreatedByID != user.ID {
-			passResponse(rw, "You must be elevated or the issue creator to delete it", false, http.StatusForbidden)
-
-			return
-		}
-
-		_, err = er.Postgres.Model(issue).
-			WherePK().
-			Delete()
-		if err != nil {
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
-
-			return
-		}
-
-		switch issue.Type {
-		case structs.EntryActive:
-			project.ActiveIssues--
-		case structs.EntryOpen:
-			project.OpenIssues--
-		case structs.EntryResolved:
-			project.ResolvedIssues--
-		case structs.EntryInvalid:
-		}
-
-		// Update issues cache counter on project
-		_, err = er.Postgres.Model(project).
-			WherePK().
-			Update()
-		if err != nil {
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
-
-			return
-		}
-
-		_, err = er.Postgres.Model(&structs.Comment{}).
-			Where("issue_id = ?", issue.ID).
-			Delete()
-		if err != nil {
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+		if res.RowsAffected() == 0 {
+			passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
 
 			return
 		}
 
-		passResponse(rw, "Issue was deleted", true, http.StatusOK)
+		passResponse(rw, "OK", true, http.StatusOK)
 	}
 }
 
-// APIProjectIssueCommentHandler returns a list of comments from an issue and returns paginated results.
-func APIProjectIssueCommentHandler(er *Errorly) http.HandlerFunc {
+// APIProjectInviteCreateHandler handles creating an invite.
+func APIProjectInviteCreateHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		_issueID, ok := vars["issue_id"]
-		if !ok {
-			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
+		// Authenticate the user
+		auth, user := er.AuthenticateSession(session)
+		if !auth {
+			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
 
 			return
 		}
 
-		// Authenticate the user
-		auth, user := er.AuthenticateSession(session)
-
-		project, viewable, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
 		if !ok {
 			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
 			return
@@ -2320,94 +6200,80 @@ func APIProjectIssueCommentHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		// If an ID is specified we will instead return just the issue
-		issueID, err := strconv.ParseInt(_issueID, 10, 64)
-		if err != nil {
-			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
 
 			return
 		}
 
-		issue := &structs.IssueEntry{}
+		expiration, err := strconv.ParseInt(r.FormValue("expiration"), 10, 64)
+		if err != nil {
+			passResponse(rw, "Expiration argument is not valid", false, http.StatusBadRequest)
 
-		err = er.Postgres.Model(issue).
-			Where("issue_entry.project_id = ?", project.ID).
-			Where("issue_entry.id = ?", issueID).
-			Select()
+			return
+		}
+
+		uses, err := strconv.ParseInt(r.FormValue("uses"), 10, 64)
 		if err != nil {
-			if errors.Is(err, pg.ErrNoRows) {
-				// Invalid issue ID
-				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
+			passResponse(rw, "Expiration argument is not valid", false, http.StatusBadRequest)
 
-				return
-			}
+			return
+		}
 
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+		if expiration < 0 || uses < 0 {
+			passResponse(rw, "Invalid argument for either expiration or uses", false, http.StatusBadRequest)
 
 			return
 		}
 
-		// We will use the same page limit for the issues per query limit
-		_issueLimit := pageLimit
+		now := time.Now()
+
+		var expiresBy time.Time
+
+		if expiration > 0 {
+			expiresBy = now.Add(time.Duration(expiration) * time.Minute)
+		} else {
+			expiresBy = time.Time{}
+		}
+
+		id := er.IDGen.GenerateID()
 
-		// Retrieve page argument from URL
-		_page := r.URL.Query().Get("page")
-		if _page == "" {
-			_page = "0"
-		}
+		invite := structs.InviteCode{
+			ID:   id,
+			Code: idFromUInt64(uint64(id)),
 
-		// Check page is a valid number. We will use the first page
-		// argument provided as multiple could be passed.
-		page, err := strconv.Atoi(_page)
-		if err != nil {
-			passResponse(rw, "Page argument is not valid", false, http.StatusBadRequest)
+			CreatedAt:   now,
+			CreatedByID: user.ID,
 
-			return
-		}
+			Uses:    0,
+			MaxUses: uses,
 
-		comments := make([]structs.Comment, 0, _issueLimit)
+			ProjectID: project.ID,
+			ExpiresBy: expiresBy,
+		}
 
-		err = er.Postgres.Model(&comments).
-			Limit(_issueLimit).
-			Where("issue_id = ?", issue.ID).
-			Offset(int(math.Max(0, float64(_issueLimit*page)))).
-			Select()
+		_, err = er.Postgres.Model(&invite).Insert()
 		if err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to insert invite")
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		passResponse(rw, structs.APIProjectIssueComments{
-			Page:     page,
-			Comments: comments,
-			End:      len(comments) < _issueLimit,
-		}, true, http.StatusOK)
+		passResponse(rw, invite, true, http.StatusOK)
 	}
 }
 
-// APIProjectIssueCommentCreateHandler handles the creation of issue comments.
-func APIProjectIssueCommentCreateHandler(er *Errorly) http.HandlerFunc {
+// APIProjectIntegrationCreate handles creating an integration.
+func APIProjectIntegrationCreate(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		_issueID, ok := vars["issue_id"]
-		if !ok {
-			passResponse(rw, "Missing Issue ID", false, http.StatusBadRequest)
-
-			return
-		}
-
-		if err := r.ParseForm(); err != nil {
-			er.Logger.Error().Err(err).Msg("Failed to parse form")
-			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
-
-			return
-		}
-
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
 		if !auth {
@@ -2430,101 +6296,66 @@ func APIProjectIssueCommentCreateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		if project.Settings.Limited && !elevated {
-			passResponse(rw, "You need to be a contributor of this project to create issues", false, http.StatusForbidden)
-
-			return
-		}
-
-		if project.Settings.Archived {
-			passResponse(rw, "This project is archived", false, http.StatusForbidden)
-
-			return
-		}
-
-		// If an ID is specified we will instead return just the issue
-		issueID, err := strconv.ParseInt(_issueID, 10, 64)
-		if err != nil {
-			passResponse(rw, "ID argument is not valid", false, http.StatusBadRequest)
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
 
 			return
 		}
 
-		issue := &structs.IssueEntry{}
-
-		err = er.Postgres.Model(issue).
-			Where("issue_entry.project_id = ?", project.ID).
-			Where("issue_entry.id = ?", issueID).
-			Select()
-		if err != nil {
-			if errors.Is(err, pg.ErrNoRows) {
-				// Invalid issue ID
-				passResponse(rw, "Could not find this issue", false, http.StatusBadRequest)
-
-				return
-			}
-
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+		integrationName := r.FormValue("display_name")
+		if len(integrationName) < 3 {
+			passResponse(rw, "Invalid name was passed", false, http.StatusBadRequest)
 
 			return
 		}
 
-		if issue.CommentsLocked {
-			passResponse(rw, "Comments are locked for this issue", false, http.StatusForbidden)
-
-			return
-		}
+		integration := &structs.User{
+			ID:   er.IDGen.GenerateID(),
+			Name: integrationName,
 
-		content := strings.TrimSpace(r.PostFormValue("content"))
-		if len(content) == 0 {
-			passResponse(rw, "Invalid message content was passed", false, http.StatusBadRequest)
+			UserType: structs.IntegrationUser,
 
-			return
-		}
+			CreatedAt: time.Now().UTC(),
 
-		now := time.Now().UTC()
-		comment := &structs.Comment{
-			ID:          er.IDGen.GenerateID(),
-			IssueID:     issue.ID,
-			CreatedAt:   now,
+			ProjectID:   project.ID,
 			CreatedByID: user.ID,
-			Type:        structs.Message,
-			Content:     &content,
+			Integration: true,
 		}
 
-		_, err = er.Postgres.Model(comment).Insert()
+		_, rand := CreateUserToken(integration)
+		integration.Token = rand
+
+		_, err := er.Postgres.Model(integration).
+			Insert()
 		if err != nil {
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		err = er.HandleProjectWebhook(project, structs.WebhookMessage{
-			Type:    structs.IssueComment,
-			Project: project,
-			Issue:   issue,
-			Author:  user,
-			Comment: comment,
-		})
+		err = recordAudit(er, r, project, user, structs.AuditIntegrationCreate,
+			strconv.FormatInt(integration.ID, 10), nil, integration)
 		if err != nil {
-			er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
 		}
 
-		passResponse(rw, comment, true, http.StatusOK)
+		passResponse(rw, integration, true, http.StatusOK)
 	}
 }
 
-// APIProjectInviteGetHandler handles retrieving an invite.
-func APIProjectInviteGetHandler(er *Errorly) http.HandlerFunc {
+// APIProjectIntegrationDelete handles deleting an integration.
+func APIProjectIntegrationDelete(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		inviteCode, ok := vars["join_code"]
+		integrationID, ok := vars["integration_id"]
 		if !ok {
-			passResponse(rw, "No invite code supplied", false, http.StatusBadRequest)
+			passResponse(rw, "No integration id supplied", false, http.StatusBadRequest)
 
 			return
 		}
@@ -2537,21 +6368,39 @@ func APIProjectInviteGetHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		project, _, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
 		if !ok {
 			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
 			return
 		}
 
-		invite := &structs.InviteCode{}
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
 
-		err := er.Postgres.Model(invite).
-			Where("code = ?", inviteCode).
+			return
+		}
+
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
+
+			return
+		}
+
+		integration := &structs.User{}
+
+		_, err := er.Postgres.Model(integration).
+			Where("id = ?", integrationID).
 			Where("project_id = ?", project.ID).
-			Select()
+			Where("user_type = ?", structs.IntegrationUser).
+			Returning("*").
+			Delete()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
+				passResponse(rw, "Invalid integration id passed", false, http.StatusBadRequest)
 
 				return
 			}
@@ -2562,24 +6411,26 @@ func APIProjectInviteGetHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		passResponse(rw, structs.APIProjectInviteGet{
-			ValidInvite: true,
-			ProjectName: project.Settings.DisplayName,
-		}, true, http.StatusOK)
+		err = recordAudit(er, r, project, user, structs.AuditIntegrationDelete, integrationID, integration, nil)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
+		passResponse(rw, "OK", true, http.StatusOK)
 	}
 }
 
-// APIProjectInviteUseHandler handles a user joining an invite.
-func APIProjectInviteUseHandler(er *Errorly) http.HandlerFunc {
+// APIProjectIntegrationRegenerate handles regenerating an integration token.
+func APIProjectIntegrationRegenerate(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		inviteCode, ok := vars["join_code"]
+		integrationID, ok := vars["integration_id"]
 		if !ok {
-			passResponse(rw, "No invite code supplied", false, http.StatusBadRequest)
+			passResponse(rw, "No integration id supplied", false, http.StatusBadRequest)
 
 			return
 		}
@@ -2592,111 +6443,86 @@ func APIProjectInviteUseHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		project, _, _, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
+		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
 		if !ok {
 			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
 			return
 		}
 
-		if project.CreatedByID == user.ID {
-			passResponse(rw, "You are already in this project", false, http.StatusBadRequest)
+		if !viewable {
+			// No permission to view project. We will treat like the project
+			// does not exist.
+			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
 
 			return
 		}
 
-		for _, contributor := range project.Settings.ContributorIDs {
-			if contributor == user.ID {
-				passResponse(rw, "You are already in this project", false, http.StatusBadRequest)
+		if !elevated {
+			// No permission to execute on project. We will simply tell them
+			// they cannot do this.
+			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
 
-				return
-			}
+			return
 		}
 
-		invite := &structs.InviteCode{}
+		integration := &structs.User{}
 
-		err := er.Postgres.Model(invite).
+		err := er.Postgres.Model(integration).
+			Where("id = ?", integrationID).
 			Where("project_id = ?", project.ID).
-			Where("code = ?", inviteCode).
+			Where("user_type = ?", structs.IntegrationUser).
 			Select()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
-
-				return
-			}
-
-			// Unexpected error
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
-
-			return
-		}
-
-		// Check if the invite has enough uses or has expired, if so delete it.
-		if (invite.MaxUses > 0 && invite.Uses >= invite.MaxUses) ||
-			(!invite.ExpiresBy.IsZero() && time.Now().After(invite.ExpiresBy)) {
-			_, err = er.Postgres.Model(invite).
-				WherePK().
-				Delete()
-			if err != nil && !errors.Is(err, pg.ErrNoRows) {
-				// Unexpected error
-				passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+				// Invalid project ID
+				ok = false
+				passResponse(rw, "Could not find this integration", false, http.StatusBadRequest)
 
 				return
 			}
 
-			passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
-
-			return
-		}
-
-		// Increment invite uses
-		invite.Uses++
-
-		// Add user to contributors
-		project.Settings.ContributorIDs = append(project.Settings.ContributorIDs, user.ID)
-
-		// Add project to users project list
-		user.ProjectIDs = append(user.ProjectIDs, project.ID)
-
-		_, err = er.Postgres.Model(project).WherePK().Update()
-		if err != nil && !errors.Is(err, pg.ErrNoRows) {
-			// Unexpected error
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
-
-			return
-		}
-
-		_, err = er.Postgres.Model(invite).WherePK().Update()
-		if err != nil && !errors.Is(err, pg.ErrNoRows) {
 			// Unexpected error
+			ok = false
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		_, err = er.Postgres.Model(user).WherePK().Update()
-		if err != nil && !errors.Is(err, pg.ErrNoRows) {
-			// Unexpected error
+		_, rand := CreateUserToken(integration)
+		integration.Token = rand
+
+		_, err = er.Postgres.Model(integration).
+			WherePK().
+			Update()
+		if err != nil {
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		passResponse(rw, "OK", true, http.StatusOK)
+		// The token is not included in the JSON representation of the
+		// integration so the change is recorded explicitly.
+		err = recordAudit(er, r, project, user, structs.AuditIntegrationRegenerate, integrationID,
+			map[string]string{"token": "previous"}, map[string]string{"token": "regenerated"})
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
+		passResponse(rw, integration, true, http.StatusOK)
 	}
 }
 
-// APIProjectInviteDeleteHandler handles deleting an invite.
-func APIProjectInviteDeleteHandler(er *Errorly) http.HandlerFunc {
+// APIProjectIntegrationTokenHandler handles returning an integration token.
+func APIProjectIntegrationTokenHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		inviteCode, ok := vars["join_code"]
+		integrationID, ok := vars["integration_id"]
 		if !ok {
-			passResponse(rw, "No invite code supplied", false, http.StatusBadRequest)
+			passResponse(rw, "No integration id supplied", false, http.StatusBadRequest)
 
 			return
 		}
@@ -2731,37 +6557,186 @@ func APIProjectInviteDeleteHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		invite := &structs.InviteCode{}
+		integration := &structs.User{}
 
-		res, err := er.Postgres.Model(invite).
-			Where("code = ?", inviteCode).
+		err := er.Postgres.Model(integration).
+			Where("id = ?", integrationID).
 			Where("project_id = ?", project.ID).
-			Delete()
+			Where("user_type = ?", structs.IntegrationUser).
+			Select()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
+				// Invalid project ID
+				ok = false
+				passResponse(rw, "Could not find this integration", false, http.StatusBadRequest)
 
 				return
 			}
 
 			// Unexpected error
+			ok = false
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		if res.RowsAffected() == 0 {
-			passResponse(rw, "Invalid invite code passed", false, http.StatusBadRequest)
+		res := make([]byte, 8)
+		binary.BigEndian.PutUint64(res, uint64(integration.ID))
+		token := base64.URLEncoding.EncodeToString(res) + "." + integration.Token
 
-			return
+		passResponse(rw, token, true, http.StatusOK)
+	}
+}
+
+// splitFormList splits a comma separated form value, ignoring empty values.
+func splitFormList(value string) (values []string) {
+	values = make([]string, 0)
+
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
 		}
+	}
 
-		passResponse(rw, "OK", true, http.StatusOK)
+	return values
+}
+
+// parseWebhookType returns the webhook type passed in a request. The type
+// can be passed by name or, for older clients, with webhook_discord.
+// Returns false if no type was passed.
+func parseWebhookType(r *http.Request) (webhookType structs.WebhookType, ok bool, err error) {
+	if _type := r.FormValue("type"); _type != "" {
+		webhookType, err = structs.ParseWebhookType(strings.ToLower(_type))
+
+		return webhookType, err == nil, err
+	}
+
+	if _webhookDiscord := r.FormValue("webhook_discord"); _webhookDiscord != "" {
+		webhookDiscord, err := strconv.ParseBool(_webhookDiscord)
+		if err != nil {
+			return structs.RegularPayload, false, err
+		}
+
+		if webhookDiscord {
+			return structs.DiscordWebhook, true, nil
+		}
+
+		return structs.RegularPayload, true, nil
 	}
+
+	return structs.RegularPayload, false, nil
 }
 
-// APIProjectInviteCreateHandler handles creating an invite.
-func APIProjectInviteCreateHandler(er *Errorly) http.HandlerFunc {
+// parseWebhookTemplateForm updates the template and template content type
+// of a webhook from a request. Only the passed values are changed. If the
+// webhook is a TemplateWebhook, the template is validated. The returned
+// error can be shown to the user.
+func parseWebhookTemplateForm(er *Errorly, r *http.Request, webhook *structs.Webhook) (err error) {
+	if _template, ok := r.Form["template"]; ok {
+		webhook.Template = _template[0]
+	}
+
+	if _contentType, ok := r.Form["template_content_type"]; ok {
+		contentType := strings.TrimSpace(_contentType[0])
+
+		err = validateTemplateContentType(contentType)
+		if err != nil {
+			return err
+		}
+
+		webhook.TemplateContentType = contentType
+	}
+
+	if webhook.Type == structs.TemplateWebhook {
+		_, err = er.parseWebhookTemplate(webhook.Template)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseWebhookCloudEvents updates the CloudEvents mode of a webhook from a
+// request. The returned error can be shown to the user.
+func parseWebhookCloudEvents(r *http.Request, webhook *structs.Webhook) (err error) {
+	if _cloudEvents := r.FormValue("cloudevents"); _cloudEvents != "" {
+		mode, err := structs.ParseCloudEventsMode(strings.ToLower(_cloudEvents))
+		if err != nil {
+			return xerrors.New("Passed cloudevents value is not valid")
+		}
+
+		webhook.CloudEvents = mode
+	}
+
+	return nil
+}
+
+// parseWebhookRoutingKey updates the pagerduty routing key of a webhook
+// from a request. A routing key is required if the webhook is a
+// PagerDutyWebhook. The returned error can be shown to the user.
+func parseWebhookRoutingKey(r *http.Request, webhook *structs.Webhook) (err error) {
+	if _routingKey, ok := r.Form["routing_key"]; ok {
+		webhook.RoutingKey = strings.TrimSpace(_routingKey[0])
+	}
+
+	if webhook.Type == structs.PagerDutyWebhook && webhook.RoutingKey == "" {
+		return xerrors.New("A routing_key is required for pagerduty webhooks")
+	}
+
+	return nil
+}
+
+// parseWebhookSubscriptions updates the events and filters of a webhook
+// from a request. Only the passed values are changed. The returned error
+// can be shown to the user.
+func parseWebhookSubscriptions(r *http.Request, webhook *structs.Webhook) (err error) {
+	if _events, ok := r.Form["events"]; ok {
+		var events structs.WebhookEventMask
+
+		for _, name := range splitFormList(_events[0]) {
+			eventType, err := structs.ParseWebhookEventType(name)
+			if err != nil {
+				return xerrors.New("Passed events value is not valid")
+			}
+
+			events = events.Add(eventType)
+		}
+
+		webhook.Events = events
+	}
+
+	if _environments, ok := r.Form["environments"]; ok {
+		webhook.Filters.Environments = splitFormList(_environments[0])
+	}
+
+	if _minPriority, ok := r.Form["min_priority"]; ok {
+		minPriority, err := structs.ParsePriority(strings.ToLower(_minPriority[0]))
+		if err != nil {
+			return xerrors.New("Passed min_priority value is not valid")
+		}
+
+		webhook.Filters.MinPriority = minPriority
+	}
+
+	if _labels, ok := r.Form["labels"]; ok {
+		webhook.Filters.Labels = splitFormList(_labels[0])
+	}
+
+	if _query, ok := r.Form["query"]; ok {
+		query := strings.TrimSpace(_query[0])
+		if _, err := splitIssueQuery(query); err != nil {
+			return xerrors.New("Passed query value is not valid")
+		}
+
+		webhook.Filters.Query = query
+	}
+
+	return nil
+}
+
+// APIProjectWebhookCreateHandler handles creating a webhook.
+func APIProjectWebhookCreateHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
@@ -2798,72 +6773,110 @@ func APIProjectInviteCreateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		expiration, err := strconv.ParseInt(r.FormValue("expiration"), 10, 64)
+		webhookURL, err := parseWebhookURL(r.FormValue("url"))
 		if err != nil {
-			passResponse(rw, "Expiration argument is not valid", false, http.StatusBadRequest)
+			passResponse(rw, "Webhook url is not valid", false, http.StatusBadRequest)
 
 			return
 		}
 
-		uses, err := strconv.ParseInt(r.FormValue("uses"), 10, 64)
+		webhookSecret := r.FormValue("secret")
+
+		useJSON, err := strconv.ParseBool(r.FormValue("use_json"))
 		if err != nil {
-			passResponse(rw, "Expiration argument is not valid", false, http.StatusBadRequest)
+			passResponse(rw, "Passed use_json value is not valid", false, http.StatusBadRequest)
 
 			return
 		}
 
-		if expiration < 0 || uses < 0 {
-			passResponse(rw, "Invalid argument for either expiration or uses", false, http.StatusBadRequest)
+		webhookType, ok, err := parseWebhookType(r)
+		if err != nil || !ok {
+			passResponse(rw, "Passed type value is not valid", false, http.StatusBadRequest)
 
 			return
 		}
 
-		now := time.Now()
+		webhook := &structs.Webhook{
+			ID:        er.IDGen.GenerateID(),
+			ProjectID: project.ID,
 
-		var expiresBy time.Time
+			CreatedAt:   time.Now().UTC(),
+			CreatedByID: user.ID,
 
-		if expiration > 0 {
-			expiresBy = now.Add(time.Duration(expiration) * time.Minute)
-		} else {
-			expiresBy = time.Time{}
+			Secret:      webhookSecret,
+			URL:         webhookURL.String(),
+			Type:        webhookType,
+			JSONContent: useJSON,
+			Active:      true,
+
+			Failures: 0,
 		}
 
-		id := er.IDGen.GenerateID()
+		err = parseWebhookSubscriptions(r, webhook)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
 
-		invite := structs.InviteCode{
-			ID:   id,
-			Code: idFromUInt64(uint64(id)),
+			return
+		}
 
-			CreatedAt:   now,
-			CreatedByID: user.ID,
+		err = parseWebhookTemplateForm(er, r, webhook)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
 
-			Uses:    0,
-			MaxUses: uses,
+			return
+		}
 
-			ProjectID: project.ID,
-			ExpiresBy: expiresBy,
+		err = parseWebhookCloudEvents(r, webhook)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
+
+			return
 		}
 
-		_, err = er.Postgres.Model(&invite).Insert()
+		err = parseWebhookRoutingKey(r, webhook)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
+
+			return
+		}
+
+		_, err = er.Postgres.Model(webhook).
+			Insert()
 		if err != nil {
-			er.Logger.Error().Err(err).Msg("Failed to insert invite")
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		passResponse(rw, invite, true, http.StatusOK)
+		err = recordAudit(er, r, project, user, structs.AuditWebhookCreate,
+			strconv.FormatInt(webhook.ID, 10), nil, webhook)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
+		passResponse(rw, webhook, true, http.StatusOK)
 	}
 }
 
-// APIProjectIntegrationCreate handles creating an integration.
-func APIProjectIntegrationCreate(er *Errorly) http.HandlerFunc {
+// APIProjectWebhookUpdateHandler handles updating a webhook. Only the
+// passed values are changed. Enabling a webhook that was disabled after
+// failing will reset its failures.
+func APIProjectWebhookUpdateHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
+
+			return
+		}
+
 		vars := mux.Vars(r)
 
+		webhookID := vars["webhook_id"]
+
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
 		if !auth {
@@ -2894,120 +6907,149 @@ func APIProjectIntegrationCreate(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		integrationName := r.FormValue("display_name")
-		if len(integrationName) < 3 {
-			passResponse(rw, "Invalid name was passed", false, http.StatusBadRequest)
+		webhook := &structs.Webhook{}
+
+		err := er.Postgres.Model(webhook).
+			Where("id = ?", webhookID).
+			Where("project_id = ?", project.ID).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
+
+				return
+			}
+
+			// Unexpected error
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		integration := &structs.User{
-			ID:   er.IDGen.GenerateID(),
-			Name: integrationName,
+		originalWebhook := *webhook
 
-			UserType: structs.IntegrationUser,
+		if _url, ok := r.Form["url"]; ok {
+			webhookURL, err := parseWebhookURL(_url[0])
+			if err != nil {
+				passResponse(rw, "Webhook url is not valid", false, http.StatusBadRequest)
 
-			CreatedAt: time.Now().UTC(),
+				return
+			}
 
-			ProjectID:   project.ID,
-			CreatedByID: user.ID,
-			Integration: true,
+			webhook.URL = webhookURL.String()
 		}
 
-		_, rand := CreateUserToken(integration)
-		integration.Token = rand
+		// An empty secret will remove the secret from the webhook. The
+		// previous secret is kept signing deliveries for a while so
+		// receivers have time to start using the new one.
+		if _secret, ok := r.Form["secret"]; ok && _secret[0] != webhook.Secret {
+			if webhook.Secret != "" {
+				webhook.PreviousSecret = webhook.Secret
+				webhook.PreviousSecretExpiresAt = time.Now().UTC().Add(webhookSecretRotationPeriod)
+			}
 
-		_, err := er.Postgres.Model(integration).
-			Insert()
-		if err != nil {
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+			webhook.Secret = _secret[0]
 		}
 
-		passResponse(rw, integration, true, http.StatusOK)
-	}
-}
+		if _revoke, err := strconv.ParseBool(r.FormValue("revoke_previous_secret")); err == nil && _revoke {
+			webhook.PreviousSecret = ""
+			webhook.PreviousSecretExpiresAt = time.Time{}
+		}
 
-// APIProjectIntegrationDelete handles deleting an integration.
-func APIProjectIntegrationDelete(er *Errorly) http.HandlerFunc {
-	return func(rw http.ResponseWriter, r *http.Request) {
-		session, _ := er.Store.Get(r, sessionName)
-		defer er.SaveSession(session, r, rw)
+		if _useJSON := r.FormValue("use_json"); _useJSON != "" {
+			useJSON, err := strconv.ParseBool(_useJSON)
+			if err != nil {
+				passResponse(rw, "Passed use_json value is not valid", false, http.StatusBadRequest)
 
-		vars := mux.Vars(r)
+				return
+			}
 
-		integrationID, ok := vars["integration_id"]
-		if !ok {
-			passResponse(rw, "No integration id supplied", false, http.StatusBadRequest)
+			webhook.JSONContent = useJSON
+		}
+
+		webhookType, ok, err := parseWebhookType(r)
+		if err != nil {
+			passResponse(rw, "Passed type value is not valid", false, http.StatusBadRequest)
 
 			return
 		}
 
-		// Authenticate the user
-		auth, user := er.AuthenticateSession(session)
-		if !auth {
-			passResponse(rw, "You must be logged in to do this", false, http.StatusForbidden)
+		if ok {
+			webhook.Type = webhookType
+		}
+
+		if _active := r.FormValue("active"); _active != "" {
+			active, err := strconv.ParseBool(_active)
+			if err != nil {
+				passResponse(rw, "Passed active value is not valid", false, http.StatusBadRequest)
+
+				return
+			}
+
+			// Re-enabling a webhook gives it a fresh start, otherwise it
+			// would be disabled again after its next failure.
+			if active && !webhook.Active {
+				webhook.Failures = 0
+			}
 
-			return
+			webhook.Active = active
 		}
 
-		project, viewable, elevated, ok := verifyProjectVisibility(er, rw, vars, user, auth, true)
-		if !ok {
-			// If ok is False, an error has already been provided to the ResponseWriter so we should just return
+		err = parseWebhookSubscriptions(r, webhook)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
+
 			return
 		}
 
-		if !viewable {
-			// No permission to view project. We will treat like the project
-			// does not exist.
-			passResponse(rw, "Could not find this project", false, http.StatusBadRequest)
+		err = parseWebhookTemplateForm(er, r, webhook)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
 
 			return
 		}
 
-		if !elevated {
-			// No permission to execute on project. We will simply tell them
-			// they cannot do this.
-			passResponse(rw, "Guests to a project cannot do this", false, http.StatusForbidden)
+		err = parseWebhookCloudEvents(r, webhook)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
 
 			return
 		}
 
-		_, err := er.Postgres.Model(&structs.User{}).
-			Where("id = ?", integrationID).
-			Where("project_id = ?", project.ID).
-			Where("user_type = ?", structs.IntegrationUser).
-			Delete()
+		err = parseWebhookRoutingKey(r, webhook)
 		if err != nil {
-			if errors.Is(err, pg.ErrNoRows) {
-				passResponse(rw, "Invalid integration id passed", false, http.StatusBadRequest)
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
 
-				return
-			}
+			return
+		}
 
-			// Unexpected error
+		_, err = er.Postgres.Model(webhook).
+			WherePK().
+			Update()
+		if err != nil {
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		passResponse(rw, "OK", true, http.StatusOK)
+		err = recordAudit(er, r, project, user, structs.AuditWebhookUpdate, webhookID, originalWebhook, webhook)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
+		passResponse(rw, webhook, true, http.StatusOK)
 	}
 }
 
-// APIProjectIntegrationRegenerate handles regenerating an integration token.
-func APIProjectIntegrationRegenerate(er *Errorly) http.HandlerFunc {
+// APIProjectWebhookDeleteHandler handles deleting a webhook.
+func APIProjectWebhookDeleteHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		integrationID, ok := vars["integration_id"]
-		if !ok {
-			passResponse(rw, "No integration id supplied", false, http.StatusBadRequest)
-
-			return
-		}
+		webhookID := vars["webhook_id"]
 
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
@@ -3039,59 +7081,68 @@ func APIProjectIntegrationRegenerate(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		integration := &structs.User{}
+		webhook := &structs.Webhook{}
 
-		err := er.Postgres.Model(integration).
-			Where("id = ?", integrationID).
+		res, err := er.Postgres.Model(webhook).
+			Where("id = ?", webhookID).
 			Where("project_id = ?", project.ID).
-			Where("user_type = ?", structs.IntegrationUser).
-			Select()
+			Returning("*").
+			Delete()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				// Invalid project ID
-				ok = false
-				passResponse(rw, "Could not find this integration", false, http.StatusBadRequest)
+				passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
 
 				return
 			}
 
 			// Unexpected error
-			ok = false
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		_, rand := CreateUserToken(integration)
-		integration.Token = rand
+		if res.RowsAffected() == 0 {
+			passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
 
-		_, err = er.Postgres.Model(integration).
-			WherePK().
-			Update()
+			return
+		}
+
+		_, err = er.Postgres.Model(&structs.WebhookDelivery{}).
+			Where("webhook_id = ?", webhook.ID).
+			Delete()
 		if err != nil {
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		passResponse(rw, integration, true, http.StatusOK)
+		_, err = er.Postgres.Model(&structs.WebhookDeliveryAttempt{}).
+			Where("webhook_id = ?", webhook.ID).
+			Delete()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		err = recordAudit(er, r, project, user, structs.AuditWebhookDelete, webhookID, webhook, nil)
+		if err != nil {
+			er.Logger.Warn().Err(err).Msg("Failed to record audit log")
+		}
+
+		passResponse(rw, "OK", true, http.StatusOK)
 	}
 }
 
-// APIProjectIntegrationTokenHandler handles returning an integration token.
-func APIProjectIntegrationTokenHandler(er *Errorly) http.HandlerFunc {
+// APIProjectWebhookTestHandler handles testing a webhook.
+func APIProjectWebhookTestHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
-		integrationID, ok := vars["integration_id"]
-		if !ok {
-			passResponse(rw, "No integration id supplied", false, http.StatusBadRequest)
-
-			return
-		}
+		webhookID := vars["webhook_id"]
 
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
@@ -3123,45 +7174,72 @@ func APIProjectIntegrationTokenHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		integration := &structs.User{}
+		webhook := &structs.Webhook{}
 
-		err := er.Postgres.Model(integration).
-			Where("id = ?", integrationID).
+		err := er.Postgres.Model(webhook).
+			Where("id = ?", webhookID).
 			Where("project_id = ?", project.ID).
-			Where("user_type = ?", structs.IntegrationUser).
 			Select()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
-				// Invalid project ID
-				ok = false
-				passResponse(rw, "Could not find this integration", false, http.StatusBadRequest)
+				passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
 
 				return
 			}
 
 			// Unexpected error
-			ok = false
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		res := make([]byte, 8)
-		binary.BigEndian.PutUint64(res, uint64(integration.ID))
-		token := base64.URLEncoding.EncodeToString(res) + "." + integration.Token
+		ok, webhookErr := er.DoTestWebhook(webhook)
+		if !ok || webhookErr != nil {
+			webhook.Failures++
+		} else {
+			webhook.Failures = 0
+		}
 
-		passResponse(rw, token, true, http.StatusOK)
+		if webhook.Failures >= webhookMaxFailures {
+			webhook.Active = false
+		} else {
+			webhook.Active = true
+		}
+
+		_, err = er.Postgres.Model(webhook).
+			Column("failures", "active").
+			WherePK().
+			Update()
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		resp := structs.WebhookTest{
+			OK:      ok,
+			Webhook: webhook,
+		}
+
+		if webhookErr != nil {
+			resp.Error = webhookErr.Error()
+		}
+
+		passResponse(rw, resp, true, http.StatusOK)
 	}
 }
 
-// APIProjectWebhookCreateHandler handles creating a webhook.
-func APIProjectWebhookCreateHandler(er *Errorly) http.HandlerFunc {
+// APIProjectWebhookDeliveriesHandler returns the deliveries of a webhook,
+// newest first, along with every attempt made to send them.
+func APIProjectWebhookDeliveriesHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
 		vars := mux.Vars(r)
 
+		webhookID := vars["webhook_id"]
+
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
 		if !auth {
@@ -3192,64 +7270,77 @@ func APIProjectWebhookCreateHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		webhookURL, err := url.Parse(r.FormValue("url"))
+		exists, err := er.Postgres.Model(&structs.Webhook{}).
+			Where("id = ?", webhookID).
+			Where("project_id = ?", project.ID).
+			Exists()
 		if err != nil {
-			passResponse(rw, "Webhook url is not valid", false, http.StatusBadRequest)
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
 
 			return
 		}
 
-		webhookSecret := r.FormValue("secret")
-
-		useJSON, err := strconv.ParseBool(r.FormValue("use_json"))
-		if err != nil {
-			passResponse(rw, "Passed use_json value is not valid", false, http.StatusBadRequest)
+		if !exists {
+			passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
 
 			return
 		}
 
-		webhookDiscord, err := strconv.ParseBool(r.FormValue("webhook_discord"))
+		limit, err := parsePageLimit(r.URL.Query().Get("limit"))
 		if err != nil {
-			passResponse(rw, "Passed webhook_discord value is not valid", false, http.StatusBadRequest)
+			passResponse(rw, "Limit argument is not valid", false, http.StatusBadRequest)
 
 			return
 		}
 
-		var webhookType structs.WebhookType
-		if webhookDiscord {
-			webhookType = structs.RegularPayload
-		} else {
-			webhookType = structs.DiscordWebhook
-		}
+		deliveries := make([]structs.WebhookDelivery, 0, limit+1)
 
-		webhook := &structs.Webhook{
-			ID:        er.IDGen.GenerateID(),
-			ProjectID: project.ID,
+		query := er.Postgres.Model(&deliveries).
+			Relation("AttemptLog", func(q *orm.Query) (*orm.Query, error) {
+				return q.Order("webhook_delivery_attempt.id ASC"), nil
+			}).
+			Where("webhook_delivery.webhook_id = ?", webhookID).
+			Order("webhook_delivery.id DESC")
 
-			CreatedAt:   time.Now().UTC(),
-			CreatedByID: user.ID,
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			values, err := decodeCursor(cursor, []string{"id"})
+			if err != nil {
+				passResponse(rw, "Cursor argument is not valid", false, http.StatusBadRequest)
 
-			Secret:      webhookSecret,
-			URL:         webhookURL.String(),
-			Type:        webhookType,
-			JSONContent: useJSON,
-			Active:      true,
+				return
+			}
 
-			Failures: 0,
+			query = applyCursor(query, []string{"webhook_delivery.id"}, []bool{true}, values)
 		}
 
-		_, err = er.Postgres.Model(webhook).
-			Insert()
+		// We will fetch an extra delivery to know if there are any more pages.
+		err = query.Limit(limit + 1).Select()
 		if err != nil {
 			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
 		}
 
-		passResponse(rw, webhook, true, http.StatusOK)
+		var nextCursor string
+
+		if len(deliveries) > limit {
+			deliveries = deliveries[:limit]
+			nextCursor = encodeCursor([]string{"id"}, []string{
+				strconv.FormatInt(deliveries[len(deliveries)-1].ID, 10),
+			})
+		}
+
+		passResponse(rw, structs.APIProjectWebhookDeliveries{
+			Limit:      limit,
+			NextCursor: nextCursor,
+			Deliveries: deliveries,
+		}, true, http.StatusOK)
 	}
 }
 
-// APIProjectWebhookDeleteHandler handles deleting a webhook.
-func APIProjectWebhookDeleteHandler(er *Errorly) http.HandlerFunc {
+// APIProjectWebhookRedeliverHandler queues the payload of a previous
+// delivery to be sent to its webhook again.
+func APIProjectWebhookRedeliverHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
@@ -3257,6 +7348,7 @@ func APIProjectWebhookDeleteHandler(er *Errorly) http.HandlerFunc {
 		vars := mux.Vars(r)
 
 		webhookID := vars["webhook_id"]
+		deliveryID := vars["delivery_id"]
 
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
@@ -3290,10 +7382,10 @@ func APIProjectWebhookDeleteHandler(er *Errorly) http.HandlerFunc {
 
 		webhook := &structs.Webhook{}
 
-		res, err := er.Postgres.Model(webhook).
+		err := er.Postgres.Model(webhook).
 			Where("id = ?", webhookID).
 			Where("project_id = ?", project.ID).
-			Delete()
+			Select()
 		if err != nil {
 			if errors.Is(err, pg.ErrNoRows) {
 				passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
@@ -3307,25 +7399,57 @@ func APIProjectWebhookDeleteHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		if res.RowsAffected() == 0 {
-			passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
+		if !webhook.Active {
+			passResponse(rw, "This webhook is disabled", false, http.StatusBadRequest)
 
 			return
 		}
 
-		passResponse(rw, "OK", true, http.StatusOK)
+		delivery := &structs.WebhookDelivery{}
+
+		err = er.Postgres.Model(delivery).
+			Where("id = ?", deliveryID).
+			Where("webhook_id = ?", webhook.ID).
+			Select()
+		if err != nil {
+			if errors.Is(err, pg.ErrNoRows) {
+				passResponse(rw, "Invalid delivery passed", false, http.StatusBadRequest)
+
+				return
+			}
+
+			// Unexpected error
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		redelivery, err := er.EnqueueWebhook(webhook, delivery.Payload)
+		if err != nil {
+			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+
+			return
+		}
+
+		passResponse(rw, redelivery, true, http.StatusOK)
 	}
 }
 
-// APIProjectWebhookTestHandler handles testing a webhook.
-func APIProjectWebhookTestHandler(er *Errorly) http.HandlerFunc {
+// APIProjectWebhookPreviewHandler renders a webhook template against an
+// example event so it can be checked before it is saved.
+func APIProjectWebhookPreviewHandler(er *Errorly) http.HandlerFunc {
 	return func(rw http.ResponseWriter, r *http.Request) {
 		session, _ := er.Store.Get(r, sessionName)
 		defer er.SaveSession(session, r, rw)
 
-		vars := mux.Vars(r)
+		if err := r.ParseForm(); err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to parse form")
+			passResponse(rw, "Failed to parse form", false, http.StatusBadRequest)
 
-		webhookID := vars["webhook_id"]
+			return
+		}
+
+		vars := mux.Vars(r)
 
 		// Authenticate the user
 		auth, user := er.AuthenticateSession(session)
@@ -3357,60 +7481,42 @@ func APIProjectWebhookTestHandler(er *Errorly) http.HandlerFunc {
 			return
 		}
 
-		webhook := &structs.Webhook{}
+		eventType := structs.IssueCreate
 
-		err := er.Postgres.Model(webhook).
-			Where("id = ?", webhookID).
-			Where("project_id = ?", project.ID).
-			Select()
-		if err != nil {
-			if errors.Is(err, pg.ErrNoRows) {
-				passResponse(rw, "Invalid webhook passed", false, http.StatusBadRequest)
+		if _event := r.FormValue("event"); _event != "" {
+			var err error
+
+			eventType, err = structs.ParseWebhookEventType(_event)
+			if err != nil {
+				passResponse(rw, "Passed event value is not valid", false, http.StatusBadRequest)
 
 				return
 			}
-
-			// Unexpected error
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
-
-			return
-		}
-
-		testPayload := structs.WebhookMessage{
-			Type: structs.TestWebhook,
-		}
-
-		ok, err = er.DoWebhook(webhook, testPayload)
-		if !ok || err != nil {
-			webhook.Failures++
-		} else {
-			webhook.Failures = 0
 		}
 
-		if webhook.Failures >= 5 {
-			webhook.Active = false
-		} else {
-			webhook.Active = true
-		}
+		contentType := strings.TrimSpace(r.FormValue("template_content_type"))
 
-		_, err = er.Postgres.Model(webhook).
-			WherePK().
-			Update()
+		err := validateTemplateContentType(contentType)
 		if err != nil {
-			passResponse(rw, err.Error(), false, http.StatusInternalServerError)
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
 
 			return
 		}
 
-		resp := structs.WebhookTest{
-			OK:      ok,
-			Webhook: webhook,
+		if contentType == "" {
+			contentType = defaultTemplateContentType
 		}
 
+		body, err := er.RenderWebhookTemplate(r.FormValue("template"), sampleWebhookMessage(project, user, eventType))
 		if err != nil {
-			resp.Error = err.Error()
+			passResponse(rw, err.Error(), false, http.StatusBadRequest)
+
+			return
 		}
 
-		passResponse(rw, resp, true, http.StatusOK)
+		passResponse(rw, structs.APIProjectWebhookPreview{
+			ContentType: contentType,
+			Body:        string(body),
+		}, true, http.StatusOK)
 	}
 }
@@ -0,0 +1,106 @@
+package errorly
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/pkg/markdown"
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+// Maximum length of a traceback shown in a matrix message.
+const matrixTracebackLength = 500
+
+// matrixSendPath is the end of the client-server API path used to send
+// m.room.message events to a room.
+const matrixSendPath = "/send/m.room.message"
+
+// ConvertErrorlyToMatrixWebhook handles converting a default payload to
+// the content of a matrix m.room.message event.
+func (er *Errorly) ConvertErrorlyToMatrixWebhook(payload structs.WebhookMessage) (bool, structs.MatrixMessage) {
+	if payload.Type == structs.TestWebhook {
+		return true, structs.MatrixMessage{
+			MsgType: "m.notice",
+			Body:    "Test Webhook",
+		}
+	}
+
+	summary, ok := er.summarizeWebhookMessage(payload)
+	if !ok {
+		return false, structs.MatrixMessage{}
+	}
+
+	var body strings.Builder
+
+	var formatted strings.Builder
+
+	body.WriteString(summary.Title + "\n" + summary.URL + "\n")
+	formatted.WriteString(`<strong><a href="` + html.EscapeString(summary.URL) + `">` +
+		html.EscapeString(summary.Title) + "</a></strong><br>\n")
+
+	if payload.Author != nil {
+		body.WriteString("By " + payload.Author.Name + "\n")
+		formatted.WriteString("<em>By " + html.EscapeString(payload.Author.Name) + "</em><br>\n")
+	}
+
+	for _, field := range summary.Fields {
+		body.WriteString(field.Name + ": " + field.Value + "\n")
+		formatted.WriteString("<strong>" + html.EscapeString(field.Name) + ":</strong> " +
+			html.EscapeString(field.Value) + "<br>\n")
+	}
+
+	if summary.Description != "" {
+		description := cutString(summary.Description, 2000)
+
+		body.WriteString("\n" + description + "\n")
+		formatted.WriteString(markdown.Render(description))
+	}
+
+	if payload.Issue.Traceback != "" {
+		traceback := cutString(payload.Issue.Traceback, matrixTracebackLength)
+
+		body.WriteString("\n" + traceback + "\n")
+		formatted.WriteString("<pre><code>" + html.EscapeString(traceback) + "</code></pre>\n")
+	}
+
+	return true, structs.MatrixMessage{
+		MsgType:       "m.notice",
+		Body:          strings.TrimSpace(body.String()),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: strings.TrimSpace(formatted.String()),
+	}
+}
+
+// matrixFormatter sends matrix m.room.message event content. Webhooks
+// pointing at the client-server send endpoint of a room, such as
+// https://matrix.org/_matrix/client/r0/rooms/{room_id}/send/m.room.message?access_token=...,
+// are sent with the delivery ID as the transaction ID so retries do not
+// post the message twice. Other urls, such as bridges, are sent the event
+// content directly.
+type matrixFormatter struct{}
+
+func (matrixFormatter) Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	ok, msg := er.ConvertErrorlyToMatrixWebhook(payload)
+	if !ok {
+		return nil, nil
+	}
+
+	req, err = jsonRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookURL, err := url.Parse(webhook.URL)
+	if err == nil && strings.HasSuffix(strings.TrimSuffix(webhookURL.Path, "/"), matrixSendPath) {
+		webhookURL.Path = strings.TrimSuffix(webhookURL.Path, "/") + "/" + strconv.FormatInt(deliveryID, 10)
+
+		req.Method = "PUT"
+		req.URL = webhookURL.String()
+	}
+
+	return req, nil
+}
@@ -0,0 +1,252 @@
+package errorly
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/pkg/markdown"
+	"github.com/TheRockettek/Errorly-Web/structs"
+	sandwich "github.com/TheRockettek/Sandwich-Daemon/structs"
+	"golang.org/x/xerrors"
+)
+
+// mentionRegex matches @name mentions in comment content.
+var mentionRegex = regexp.MustCompile(`@([\w.-]*\w)`)
+
+// discordWebhookHosts contains the hosts mentions can be delivered to.
+var discordWebhookHosts = map[string]bool{
+	"discord.com":        true,
+	"discordapp.com":     true,
+	"ptb.discord.com":    true,
+	"canary.discord.com": true,
+}
+
+// parseDiscordWebhookURL parses the url of a discord webhook. Returns an
+// error if the url is not a discord webhook.
+func parseDiscordWebhookURL(rawURL string) (webhookURL *url.URL, err error) {
+	webhookURL, err = url.Parse(rawURL)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to parse url: %w", err)
+	}
+
+	if webhookURL.Scheme != "https" || !discordWebhookHosts[strings.ToLower(webhookURL.Hostname())] ||
+		webhookURL.Port() != "" || !strings.HasPrefix(webhookURL.Path, "/api/webhooks/") {
+		return nil, xerrors.Errorf("Unsupported url: '%s'", rawURL)
+	}
+
+	return webhookURL, nil
+}
+
+// parseMentions returns the lowercase names mentioned in the content.
+func parseMentions(content string) (names map[string]bool) {
+	names = make(map[string]bool)
+
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		names[strings.ToLower(match[1])] = true
+	}
+
+	return names
+}
+
+// NotifyMentions creates a notification for every project contributor
+// mentioned in a comment. Users who have set a notification webhook
+// will also have the mention delivered to it. Returns the IDs of the
+// users who were notified.
+func (er *Errorly) NotifyMentions(project *structs.Project, issue *structs.IssueEntry,
+	comment *structs.Comment, author *structs.User) (mentioned []int64, err error) {
+	mentioned = make([]int64, 0)
+
+	if comment.Content == nil {
+		return mentioned, nil
+	}
+
+	names := parseMentions(*comment.Content)
+	if len(names) == 0 {
+		return mentioned, nil
+	}
+
+	contributorIDs := append([]int64{project.CreatedByID}, project.Settings.ContributorIDs...)
+	contributors := make([]*structs.User, 0, len(contributorIDs))
+
+	err = er.Postgres.Model(&contributors).
+		WhereIn("id IN (?)", contributorIDs).
+		Select()
+	if err != nil {
+		return mentioned, xerrors.Errorf("Failed to fetch contributors: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	for _, contributor := range contributors {
+		if contributor.ID == author.ID || !names[strings.ToLower(contributor.Name)] {
+			continue
+		}
+
+		notification := &structs.Notification{
+			ID:        er.IDGen.GenerateID(),
+			UserID:    contributor.ID,
+			ProjectID: project.ID,
+			IssueID:   issue.ID,
+			CommentID: comment.ID,
+			Type:      structs.IssueComment,
+			Reason:    structs.NotificationMentioned,
+			CreatedAt: now,
+			AuthorID:  author.ID,
+		}
+
+		_, err = er.Postgres.Model(notification).Insert()
+		if err != nil {
+			return mentioned, xerrors.Errorf("Failed to create notification: %w", err)
+		}
+
+		mentioned = append(mentioned, contributor.ID)
+
+		// Mentions are delivered in the background so a slow webhook does
+		// not hold up the comment.
+		if contributor.NotificationWebhook != "" {
+			go func(contributor *structs.User) {
+				err := er.DeliverMention(contributor, project, issue, comment, author)
+				if err != nil {
+					er.Logger.Warn().Err(err).Int64("user_id", contributor.ID).Msg("Failed to deliver mention")
+				}
+			}(contributor)
+		}
+	}
+
+	return mentioned, nil
+}
+
+// Subscribe subscribes a user to an issue. Integrations cannot subscribe
+// to issues so they are ignored.
+func (er *Errorly) Subscribe(issue *structs.IssueEntry, user *structs.User) (err error) {
+	if user == nil || user.ID == 0 || user.Integration {
+		return nil
+	}
+
+	_, err = er.Postgres.Model(&structs.IssueSubscription{
+		IssueID:   issue.ID,
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC(),
+	}).OnConflict("DO NOTHING").Insert()
+	if err != nil {
+		return xerrors.Errorf("Failed to subscribe user: %w", err)
+	}
+
+	return nil
+}
+
+// NotifySubscribers creates a notification for every user subscribed to
+// an issue except the author of the event and any excluded users. If the
+// project is private, only contributors are notified.
+func (er *Errorly) NotifySubscribers(project *structs.Project, issue *structs.IssueEntry,
+	eventType structs.WebhookEventType, comment *structs.Comment, author *structs.User, exclude ...int64) (err error) {
+	subscriptions := make([]structs.IssueSubscription, 0)
+
+	err = er.Postgres.Model(&subscriptions).
+		Where("issue_id = ?", issue.ID).
+		Select()
+	if err != nil {
+		return xerrors.Errorf("Failed to fetch subscriptions: %w", err)
+	}
+
+	excluded := make(map[int64]bool)
+	for _, userID := range exclude {
+		excluded[userID] = true
+	}
+
+	if author != nil {
+		excluded[author.ID] = true
+	}
+
+	contributors := make(map[int64]bool)
+	contributors[project.CreatedByID] = true
+
+	for _, contributorID := range project.Settings.ContributorIDs {
+		contributors[contributorID] = true
+	}
+
+	now := time.Now().UTC()
+
+	for _, subscription := range subscriptions {
+		if excluded[subscription.UserID] || (project.Settings.Private && !contributors[subscription.UserID]) {
+			continue
+		}
+
+		notification := &structs.Notification{
+			ID:        er.IDGen.GenerateID(),
+			UserID:    subscription.UserID,
+			ProjectID: project.ID,
+			IssueID:   issue.ID,
+			Type:      eventType,
+			Reason:    structs.NotificationSubscribed,
+			CreatedAt: now,
+		}
+
+		if comment != nil {
+			notification.CommentID = comment.ID
+		}
+
+		if author != nil {
+			notification.AuthorID = author.ID
+		}
+
+		_, err = er.Postgres.Model(notification).Insert()
+		if err != nil {
+			return xerrors.Errorf("Failed to create notification: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DeliverMention sends a mention to the discord webhook of a user.
+func (er *Errorly) DeliverMention(user *structs.User, project *structs.Project, issue *structs.IssueEntry,
+	comment *structs.Comment, author *structs.User) (err error) {
+	webhookURL, err := parseDiscordWebhookURL(user.NotificationWebhook)
+	if err != nil {
+		return err
+	}
+
+	res, err := json.Marshal(sandwich.WebhookMessage{
+		Embeds: []sandwich.Embed{
+			{
+				Title:       fmt.Sprintf("[%s] %s mentioned you on issue: %s", project.Settings.DisplayName, author.Name, issue.Error),
+				URL:         fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, project.ID, issue.ID),
+				Color:       issue.Priority.Color(),
+				Description: cutString(markdown.ToDiscord(*comment.Content), 2000),
+				Author: &sandwich.EmbedAuthor{
+					Name:    author.Name,
+					IconURL: author.Avatar,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return xerrors.Errorf("Failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(er.ctx, "POST", webhookURL.String(), bytes.NewBuffer(res))
+	if err != nil {
+		return xerrors.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := er.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("failed to handle request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return xerrors.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
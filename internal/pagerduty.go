@@ -0,0 +1,180 @@
+package errorly
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+// Maximum length of the summary of a pagerduty event.
+const pagerDutySummaryLength = 1024
+
+// Maximum length of a traceback sent in a pagerduty event.
+const pagerDutyTracebackLength = 2000
+
+// PagerDuty event actions.
+const (
+	pagerDutyTrigger     = "trigger"
+	pagerDutyAcknowledge = "acknowledge"
+	pagerDutyResolve     = "resolve"
+)
+
+// priorityPagerDutySeverities contains the pagerduty severity used for
+// issues of each priority.
+var priorityPagerDutySeverities = map[structs.Priority]string{
+	structs.PriorityLow:      "info",
+	structs.PriorityMedium:   "warning",
+	structs.PriorityHigh:     "error",
+	structs.PriorityCritical: "critical",
+}
+
+// pagerDutyEventAction returns the event action sent for a webhook message.
+// Issues are triggered when they occur while active or are marked active,
+// acknowledged when assigned, marked open or snoozed, and resolved when
+// marked resolved or invalid. Returns false if the message should not be
+// sent.
+func pagerDutyEventAction(payload structs.WebhookMessage) (action string, ok bool) {
+	switch payload.Type {
+	case structs.IssueCreate:
+		// Occurrences of issues that have been acknowledged or marked
+		// invalid must not trigger them again.
+		if payload.Issue.Type != structs.EntryActive {
+			return "", false
+		}
+
+		return pagerDutyTrigger, true
+	case structs.IssueAssigned:
+		// Unassigning an issue has no matching event
+		if payload.Issue.AssigneeID == 0 {
+			return "", false
+		}
+
+		return pagerDutyAcknowledge, true
+	case structs.IssueMarkStatus:
+		switch payload.Issue.Type {
+		case structs.EntryActive:
+			return pagerDutyTrigger, true
+		case structs.EntryOpen, structs.EntrySnoozed:
+			return pagerDutyAcknowledge, true
+		case structs.EntryResolved, structs.EntryInvalid:
+			return pagerDutyResolve, true
+		}
+	}
+
+	return "", false
+}
+
+// pagerDutyTestDedupKey returns the dedup key of test events of a webhook.
+func pagerDutyTestDedupKey(webhook *structs.Webhook) string {
+	return "errorly-test-" + strconv.FormatInt(webhook.ID, 10)
+}
+
+// ConvertErrorlyToPagerDutyWebhook handles converting a default payload to
+// a suitable pagerduty Events API v2 event. The dedup key of the event is
+// the fingerprint of the issue so every event of an issue applies to the
+// same alert.
+func (er *Errorly) ConvertErrorlyToPagerDutyWebhook(webhook *structs.Webhook, payload structs.WebhookMessage,
+	createdAt time.Time) (bool, structs.PagerDutyEvent) {
+	event := structs.PagerDutyEvent{
+		RoutingKey: webhook.RoutingKey,
+		Client:     "Errorly",
+		ClientURL:  er.Configuration.URL,
+	}
+
+	if payload.Type == structs.TestWebhook {
+		event.EventAction = pagerDutyTrigger
+		event.DedupKey = pagerDutyTestDedupKey(webhook)
+		event.Payload = &structs.PagerDutyPayload{
+			Summary:   "Test Webhook",
+			Source:    "Errorly",
+			Severity:  "info",
+			Timestamp: createdAt.UTC().Format(time.RFC3339),
+		}
+
+		return true, event
+	}
+
+	if payload.Project == nil || payload.Issue == nil {
+		return false, structs.PagerDutyEvent{}
+	}
+
+	action, ok := pagerDutyEventAction(payload)
+	if !ok {
+		return false, structs.PagerDutyEvent{}
+	}
+
+	event.EventAction = action
+	event.DedupKey = payload.Issue.Fingerprint()
+
+	// Acknowledge and resolve events only need the dedup key
+	if action != pagerDutyTrigger {
+		return true, event
+	}
+
+	issueURL := fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID)
+
+	customDetails := &structs.PagerDutyCustomDetails{
+		Status:        payload.Issue.Type.String(),
+		Priority:      payload.Issue.Priority.String(),
+		Occurrences:   payload.Issue.Occurrences,
+		UsersAffected: payload.Issue.UsersAffected,
+		Checkpoint:    payload.Issue.Checkpoint,
+		Release:       payload.Issue.Release,
+		Traceback:     cutString(payload.Issue.Traceback, pagerDutyTracebackLength),
+	}
+
+	severity, ok := priorityPagerDutySeverities[payload.Issue.Priority]
+	if !ok {
+		severity = "error"
+	}
+
+	event.Payload = &structs.PagerDutyPayload{
+		Summary: cutString(fmt.Sprintf("[%s] %s", payload.Project.Settings.DisplayName, payload.Issue.Error),
+			pagerDutySummaryLength-3),
+		Source:        payload.Project.Settings.DisplayName,
+		Severity:      severity,
+		Timestamp:     payload.Issue.LastSeen.UTC().Format(time.RFC3339),
+		Component:     payload.Issue.Function,
+		Group:         payload.Issue.Environment,
+		CustomDetails: customDetails,
+	}
+
+	event.Links = []*structs.PagerDutyLink{
+		{Href: issueURL, Text: "View issue on Errorly"},
+	}
+
+	return true, event
+}
+
+// pagerDutyFormatter sends pagerduty Events API v2 events.
+type pagerDutyFormatter struct{}
+
+func (pagerDutyFormatter) Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	ok, event := er.ConvertErrorlyToPagerDutyWebhook(webhook, payload, createdAt)
+	if !ok {
+		return nil, nil
+	}
+
+	return jsonRequest(event)
+}
+
+// ResolvePagerDutyTestWebhook resolves the alert triggered by a test event
+// so testing a webhook does not leave an incident open.
+func (er *Errorly) ResolvePagerDutyTestWebhook(webhook *structs.Webhook) (ok bool, err error) {
+	req, err := jsonRequest(structs.PagerDutyEvent{
+		RoutingKey:  webhook.RoutingKey,
+		EventAction: pagerDutyResolve,
+		DedupKey:    pagerDutyTestDedupKey(webhook),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	req.Method = "POST"
+	req.URL = webhook.URL
+
+	return er.ExecuteWebhook(req, nil)
+}
@@ -0,0 +1,160 @@
+package errorly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+// receivePagerDutyEvent returns the next event received by a stand-in.
+func receivePagerDutyEvent(t *testing.T, requests chan *standInRequest) structs.PagerDutyEvent {
+	t.Helper()
+
+	req := receiveStandInRequest(t, requests)
+
+	if contentType := req.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %s, want application/json", contentType)
+	}
+
+	event := structs.PagerDutyEvent{}
+
+	err := json.Unmarshal(req.Body, &event)
+	if err != nil {
+		t.Fatalf("Failed to decode pagerduty event %s: %v", req.Body, err)
+	}
+
+	if event.RoutingKey != "routing-key" {
+		t.Errorf("routing_key = %s, want routing-key", event.RoutingKey)
+	}
+
+	return event
+}
+
+// newPagerDutyStandIn returns a pagerduty webhook sending to a stand-in.
+func newPagerDutyStandIn(t *testing.T) (webhook *structs.Webhook, requests chan *standInRequest) {
+	server, requests := newWebhookStandIn(t, 202)
+
+	webhook = &structs.Webhook{
+		ID:         5,
+		URL:        server.URL,
+		Type:       structs.PagerDutyWebhook,
+		RoutingKey: "routing-key",
+	}
+
+	return webhook, requests
+}
+
+func TestPagerDutyWebhook(t *testing.T) {
+	er := newTestErrorly(t)
+	webhook, requests := newPagerDutyStandIn(t)
+
+	payload := testWebhookMessage(structs.IssueCreate)
+	fingerprint := payload.Issue.Fingerprint()
+
+	ok, err := er.DoWebhook(webhook, payload, time.Now(), nil)
+	if !ok || err != nil {
+		t.Fatalf("DoWebhook() = %t, %v, want true", ok, err)
+	}
+
+	event := receivePagerDutyEvent(t, requests)
+
+	if event.EventAction != "trigger" || event.DedupKey != fingerprint {
+		t.Errorf("event = %s %s, want trigger %s", event.EventAction, event.DedupKey, fingerprint)
+	}
+
+	if event.Payload == nil {
+		t.Fatal("Trigger has no payload")
+	}
+
+	summary := "[Welcomer] ZeroDivisionError: division by zero"
+	if event.Payload.Summary != summary || event.Payload.Source != "Welcomer" || event.Payload.Severity != "error" {
+		t.Errorf("payload = %+v, want %q from Welcomer with severity error", event.Payload, summary)
+	}
+
+	if event.Payload.Component != "calculate_average" || event.Payload.Group != "production" {
+		t.Errorf("payload = %+v, want component calculate_average in production", event.Payload)
+	}
+
+	issueURL := testURL + "/project/1/issue/2"
+	if len(event.Links) != 1 || event.Links[0].Href != issueURL {
+		t.Errorf("links = %+v, want %s", event.Links, issueURL)
+	}
+
+	// Assigning an issue acknowledges it.
+	payload = testWebhookMessage(structs.IssueAssigned)
+	payload.Issue.AssigneeID = payload.Author.ID
+
+	ok, err = er.DoWebhook(webhook, payload, time.Now(), nil)
+	if !ok || err != nil {
+		t.Fatalf("DoWebhook() = %t, %v, want true", ok, err)
+	}
+
+	event = receivePagerDutyEvent(t, requests)
+
+	if event.EventAction != "acknowledge" || event.DedupKey != fingerprint || event.Payload != nil {
+		t.Errorf("event = %+v, want acknowledge %s without a payload", event, fingerprint)
+	}
+
+	// Resolving an issue resolves it.
+	payload = testWebhookMessage(structs.IssueMarkStatus)
+	payload.Issue.Type = structs.EntryResolved
+
+	ok, err = er.DoWebhook(webhook, payload, time.Now(), nil)
+	if !ok || err != nil {
+		t.Fatalf("DoWebhook() = %t, %v, want true", ok, err)
+	}
+
+	event = receivePagerDutyEvent(t, requests)
+
+	if event.EventAction != "resolve" || event.DedupKey != fingerprint || event.Payload != nil {
+		t.Errorf("event = %+v, want resolve %s without a payload", event, fingerprint)
+	}
+}
+
+func TestPagerDutyWebhookNotSent(t *testing.T) {
+	er := newTestErrorly(t)
+	webhook, requests := newPagerDutyStandIn(t)
+
+	// Occurrences of issues that are not active do not trigger them.
+	for _, entryType := range []structs.EntryType{structs.EntryOpen, structs.EntryInvalid, structs.EntrySnoozed} {
+		payload := testWebhookMessage(structs.IssueCreate)
+		payload.Issue.Type = entryType
+
+		ok, err := er.DoWebhook(webhook, payload, time.Now(), nil)
+		if !ok || err != nil {
+			t.Fatalf("DoWebhook() = %t, %v, want true", ok, err)
+		}
+
+		expectNoStandInRequest(t, requests)
+	}
+
+	// Unassigning an issue has no matching event.
+	ok, err := er.DoWebhook(webhook, testWebhookMessage(structs.IssueAssigned), time.Now(), nil)
+	if !ok || err != nil {
+		t.Fatalf("DoWebhook() = %t, %v, want true", ok, err)
+	}
+
+	expectNoStandInRequest(t, requests)
+}
+
+func TestPagerDutyTestWebhook(t *testing.T) {
+	er := newTestErrorly(t)
+	webhook, requests := newPagerDutyStandIn(t)
+
+	ok, err := er.DoTestWebhook(webhook)
+	if !ok || err != nil {
+		t.Fatalf("DoTestWebhook() = %t, %v, want true", ok, err)
+	}
+
+	trigger := receivePagerDutyEvent(t, requests)
+	if trigger.EventAction != "trigger" || trigger.DedupKey != "errorly-test-5" {
+		t.Errorf("event = %s %s, want trigger errorly-test-5", trigger.EventAction, trigger.DedupKey)
+	}
+
+	// The test alert is resolved so no incident is left open.
+	resolve := receivePagerDutyEvent(t, requests)
+	if resolve.EventAction != "resolve" || resolve.DedupKey != trigger.DedupKey {
+		t.Errorf("event = %s %s, want resolve %s", resolve.EventAction, resolve.DedupKey, trigger.DedupKey)
+	}
+}
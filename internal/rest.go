@@ -117,6 +117,12 @@ func createEndpoints(er *Errorly) (router *MethodRouter) {
 	router.HandleFunc("/oauth2/callback", OAuthCallbackHandler(er), "GET")
 
 	router.HandleFunc("/api/me", APIMeHandler(er), "GET")
+	router.HandleFunc("/api/me/notifications", APIMeNotificationsHandler(er), "GET")
+	// Lists the users notifications
+	router.HandleFunc("/api/me/notifications/read", APIMeNotificationsReadHandler(er), "POST")
+	// Marks notifications as read or unread
+	router.HandleFunc("/api/me/notifications/webhook", APIMeNotificationsWebhookHandler(er), "POST")
+	// Sets the discord webhook mentions are delivered to
 
 	// Projects:
 	router.HandleFunc("/api/projects", APIProjectCreateHandler(er), "POST")
@@ -138,6 +144,28 @@ func createEndpoints(er *Errorly) (router *MethodRouter) {
 	// Transfers project to another user
 	router.HandleFunc("/api/project/{project_id}/contributor/{contributor}", APIProjectContributorsRemoveHandler(er), "DELETE")
 	// Removes a contributor
+	router.HandleFunc("/api/project/{project_id}/audit", APIProjectAuditHandler(er), "GET")
+	// Returns the project audit log, elevated only
+
+	// Saved searches:
+	router.HandleFunc("/api/project/{project_id}/searches", APIProjectSearchesHandler(er), "GET")
+	// Lists shared saved searches and the users own saved searches
+	router.HandleFunc("/api/project/{project_id}/searches", APIProjectSearchCreateHandler(er), "POST")
+	// Creates a saved search
+	router.HandleFunc("/api/project/{project_id}/searches/{search_id}", APIProjectSearchUpdateHandler(er), "PATCH")
+	// Updates a saved search
+	router.HandleFunc("/api/project/{project_id}/searches/{search_id}", APIProjectSearchDeleteHandler(er), "DELETE")
+	// Deletes a saved search
+
+	// Labels:
+	router.HandleFunc("/api/project/{project_id}/labels", APIProjectLabelsHandler(er), "GET")
+	// Lists project labels
+	router.HandleFunc("/api/project/{project_id}/labels", APIProjectLabelCreateHandler(er), "POST")
+	// Creates a label
+	router.HandleFunc("/api/project/{project_id}/labels/{label_id}", APIProjectLabelUpdateHandler(er), "PATCH")
+	// Updates a label
+	router.HandleFunc("/api/project/{project_id}/labels/{label_id}", APIProjectLabelDeleteHandler(er), "DELETE")
+	// Deletes a label and removes it from issues
 
 	// Issues:
 	router.HandleFunc("/api/project/{project_id}/issues", APIProjectIssueHandler(er), "GET")
@@ -154,9 +182,35 @@ func createEndpoints(er *Errorly) (router *MethodRouter) {
 	//  Lists issue comments
 	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/comments", APIProjectIssueCommentCreateHandler(er), "POST")
 	// Create issue comment
-
-	// PATCH /api/project/{project_id}/issue/{issue_id}/comments - Updates issue comment
-	// DELETE /api/project/{project_id}/issue/{issue_id}/comments - Deletes issue comment
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/timeline", APIProjectIssueTimelineHandler(er), "GET")
+	// Lists issue comments and activity interleaved
+
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/comments/{comment_id}", APIProjectIssueCommentUpdateHandler(er), "PATCH")
+	// Updates issue comment, only the author can do this.
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/comments/{comment_id}", APIProjectIssueCommentDeleteHandler(er), "DELETE")
+	// Deletes issue comment, elevated or comment author can do this.
+
+	// Reactions:
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/reactions", APIProjectReactionsHandler(er), "GET")
+	// Lists users who reacted to an issue
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/reactions/{emoji}", APIProjectReactionAddHandler(er), "PUT")
+	// Reacts to an issue
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/reactions/{emoji}", APIProjectReactionRemoveHandler(er), "DELETE")
+	// Removes reaction from an issue
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/comments/{comment_id}/reactions", APIProjectReactionsHandler(er), "GET")
+	// Lists users who reacted to a comment
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/comments/{comment_id}/reactions/{emoji}", APIProjectReactionAddHandler(er), "PUT")
+	// Reacts to a comment
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/comments/{comment_id}/reactions/{emoji}", APIProjectReactionRemoveHandler(er), "DELETE")
+	// Removes reaction from a comment
+
+	// Subscriptions:
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/subscribers", APIProjectIssueSubscribersHandler(er), "GET")
+	// Lists users subscribed to an issue
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/subscription", APIProjectIssueSubscribeHandler(er), "POST")
+	// Subscribes to an issue
+	router.HandleFunc("/api/project/{project_id}/issue/{issue_id}/subscription", APIProjectIssueUnsubscribeHandler(er), "DELETE")
+	// Unsubscribes from an issue
 
 	// Invites:
 	router.HandleFunc("/api/project/{project_id}/invite/{join_code}", APIProjectInviteGetHandler(er), "GET")
@@ -171,12 +225,18 @@ func createEndpoints(er *Errorly) (router *MethodRouter) {
 	// Webhooks:
 	router.HandleFunc("/api/project/{project_id}/webhook", APIProjectWebhookCreateHandler(er), "POST")
 	// Creates a webhook
+	router.HandleFunc("/api/project/{project_id}/webhook/preview", APIProjectWebhookPreviewHandler(er), "POST")
+	// Renders a webhook template against an example event
+	router.HandleFunc("/api/project/{project_id}/webhook/{webhook_id}", APIProjectWebhookUpdateHandler(er), "PATCH")
+	// Updates a webhook
 	router.HandleFunc("/api/project/{project_id}/webhook/{webhook_id}", APIProjectWebhookDeleteHandler(er), "DELETE")
 	// Deletes a webhook
 	router.HandleFunc("/api/project/{project_id}/webhook/{webhook_id}/test", APIProjectWebhookTestHandler(er), "POST")
 	// Tests webhook
-
-	// PATCH /api/project/{project_id}/webhook/{webhook_id} - Updates a webhook
+	router.HandleFunc("/api/project/{project_id}/webhook/{webhook_id}/deliveries", APIProjectWebhookDeliveriesHandler(er), "GET")
+	// Lists deliveries of a webhook
+	router.HandleFunc("/api/project/{project_id}/webhook/{webhook_id}/deliveries/{delivery_id}/redeliver", APIProjectWebhookRedeliverHandler(er), "POST")
+	// Sends a delivery again
 
 	// Integrations:
 	router.HandleFunc("/api/project/{project_id}/integration", APIProjectIntegrationCreate(er), "POST")
@@ -0,0 +1,129 @@
+package errorly
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+// Maximum length of a traceback shown in a slack message.
+const slackTracebackLength = 500
+
+// Maximum length of a description shown in a slack message. Slack rejects
+// section text longer than 3000 characters.
+const slackDescriptionLength = 2000
+
+// Maximum length of the title of a slack message.
+const slackTitleLength = 250
+
+// slackEscaper escapes the characters slack uses for formatting links and
+// mentions.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackText escapes text and shortens it to at most length characters.
+// Escaping can make text longer so it is escaped before it is shortened,
+// without cutting an escaped character in half.
+func slackText(text string, length int) string {
+	escaped := []rune(slackEscaper.Replace(text))
+	if len(escaped) <= length {
+		return string(escaped)
+	}
+
+	cut := string(escaped[:length-3])
+	if i := strings.LastIndexByte(cut, '&'); i != -1 && !strings.Contains(cut[i:], ";") {
+		cut = cut[:i]
+	}
+
+	return cut + "..."
+}
+
+// slackField returns a bold field name with its value below.
+func slackField(name string, value string) *structs.SlackText {
+	return &structs.SlackText{
+		Type: "mrkdwn",
+		Text: fmt.Sprintf("*%s*\n%s", name, slackEscaper.Replace(value)),
+	}
+}
+
+// ConvertErrorlyToSlackWebhook handles converting a default payload to a
+// suitable slack Block Kit payload.
+func (er *Errorly) ConvertErrorlyToSlackWebhook(payload structs.WebhookMessage) (bool, structs.SlackMessage) {
+	if payload.Type == structs.TestWebhook {
+		return true, structs.SlackMessage{
+			Text: "Test Webhook",
+		}
+	}
+
+	summary, ok := er.summarizeWebhookMessage(payload)
+	if !ok {
+		return false, structs.SlackMessage{}
+	}
+
+	blocks := []*structs.SlackBlock{
+		{
+			Type: "section",
+			Text: &structs.SlackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("*<%s|%s>*", summary.URL, slackText(summary.Title, slackTitleLength)),
+			},
+		},
+	}
+
+	if payload.Author != nil {
+		elements := make([]*structs.SlackBlockElement, 0, 2)
+
+		if payload.Author.Avatar != "" {
+			elements = append(elements, &structs.SlackBlockElement{
+				Type:     "image",
+				ImageURL: payload.Author.Avatar,
+				AltText:  payload.Author.Name,
+			})
+		}
+
+		elements = append(elements, &structs.SlackBlockElement{
+			Type: "mrkdwn",
+			Text: slackEscaper.Replace(payload.Author.Name),
+		})
+
+		blocks = append(blocks, &structs.SlackBlock{
+			Type:     "context",
+			Elements: elements,
+		})
+	}
+
+	fields := make([]*structs.SlackText, 0, len(summary.Fields))
+	for _, field := range summary.Fields {
+		fields = append(fields, slackField(field.Name, field.Value))
+	}
+
+	blocks = append(blocks, &structs.SlackBlock{
+		Type:   "section",
+		Fields: fields,
+	})
+
+	if summary.Description != "" {
+		blocks = append(blocks, &structs.SlackBlock{
+			Type: "section",
+			Text: &structs.SlackText{
+				Type: "mrkdwn",
+				Text: slackText(summary.Description, slackDescriptionLength),
+			},
+		})
+	}
+
+	if payload.Issue.Traceback != "" {
+		blocks = append(blocks, &structs.SlackBlock{
+			Type: "section",
+			Text: &structs.SlackText{
+				Type: "mrkdwn",
+				Text: "```" + slackText(payload.Issue.Traceback, slackTracebackLength) + "```",
+			},
+		})
+	}
+
+	return true, structs.SlackMessage{
+		Text:   summary.Title,
+		Blocks: blocks,
+	}
+}
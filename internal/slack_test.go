@@ -0,0 +1,150 @@
+package errorly
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+// checkSlackMessage checks a request sent to a slack webhook contains the
+// Block Kit message of a payload from testWebhookMessage.
+func checkSlackMessage(t *testing.T, req *standInRequest) {
+	t.Helper()
+
+	if req.Method != "POST" {
+		t.Errorf("Method = %s, want POST", req.Method)
+	}
+
+	if contentType := req.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %s, want application/json", contentType)
+	}
+
+	msg := structs.SlackMessage{}
+
+	err := json.Unmarshal(req.Body, &msg)
+	if err != nil {
+		t.Fatalf("Failed to decode slack message %s: %v", req.Body, err)
+	}
+
+	title := "[Welcomer] Issue opened: ZeroDivisionError: division by zero"
+	if msg.Text != title {
+		t.Errorf("Text = %q, want %q", msg.Text, title)
+	}
+
+	if len(msg.Blocks) != 5 {
+		t.Fatalf("Got %d blocks, want 5: %s", len(msg.Blocks), req.Body)
+	}
+
+	titleLink := "*<" + testURL + "/project/1/issue/2|" + title + ">*"
+	if block := msg.Blocks[0]; block.Type != "section" || block.Text == nil || block.Text.Text != titleLink {
+		t.Errorf("Title block = %+v, want section with %q", block, titleLink)
+	}
+
+	author := msg.Blocks[1]
+	if author.Type != "context" || len(author.Elements) != 2 {
+		t.Fatalf("Author block = %+v, want context with 2 elements", author)
+	}
+
+	if avatar := author.Elements[0]; avatar.Type != "image" || avatar.ImageURL != "https://cdn.example/avatar.png" {
+		t.Errorf("Author avatar = %+v, want image of the author avatar", avatar)
+	}
+
+	if name := author.Elements[1]; name.Type != "mrkdwn" || name.Text != "ImRock" {
+		t.Errorf("Author name = %+v, want ImRock", name)
+	}
+
+	fields := make([]string, 0)
+	for _, field := range msg.Blocks[2].Fields {
+		fields = append(fields, field.Text)
+	}
+
+	want := []string{"*Status*\nactive", "*Priority*\nhigh", "*Environment*\nproduction"}
+	if strings.Join(fields, "|") != strings.Join(want, "|") {
+		t.Errorf("Fields = %q, want %q", fields, want)
+	}
+
+	if description := msg.Blocks[3].Text; description == nil || description.Text != "Happens when there are no results" {
+		t.Errorf("Description block = %+v", msg.Blocks[3])
+	}
+
+	traceback := "```Traceback (most recent call last):\n  File \"stats.py\", line 42```"
+	if block := msg.Blocks[4]; block.Text == nil || block.Text.Text != traceback {
+		t.Errorf("Traceback block = %+v, want %q", block, traceback)
+	}
+}
+
+func TestSlackWebhook(t *testing.T) {
+	er := newTestErrorly(t)
+	server, requests := newWebhookStandIn(t, 200)
+
+	webhook := &structs.Webhook{
+		URL:  server.URL,
+		Type: structs.SlackWebhook,
+	}
+
+	ok, err := er.DoWebhook(webhook, testWebhookMessage(structs.IssueCreate), time.Now(), nil)
+	if !ok || err != nil {
+		t.Fatalf("DoWebhook() = %t, %v, want true", ok, err)
+	}
+
+	checkSlackMessage(t, receiveStandInRequest(t, requests))
+
+	// Unstarring an issue is not sent to slack.
+	payload := testWebhookMessage(structs.IssueStarred)
+
+	ok, err = er.DoWebhook(webhook, payload, time.Now(), nil)
+	if !ok || err != nil {
+		t.Fatalf("DoWebhook() = %t, %v, want true", ok, err)
+	}
+
+	expectNoStandInRequest(t, requests)
+}
+
+func TestSlackWebhookDelivery(t *testing.T) {
+	er := newTestErrorly(t)
+	openTestPostgres(t, er)
+
+	server, requests := newWebhookStandIn(t, 200)
+
+	webhook := &structs.Webhook{
+		URL:  server.URL,
+		Type: structs.SlackWebhook,
+	}
+	insertTestWebhook(t, er, webhook)
+
+	delivery, err := er.EnqueueWebhook(webhook, testWebhookMessage(structs.IssueCreate))
+	if err != nil {
+		t.Fatalf("EnqueueWebhook() error = %v", err)
+	}
+
+	delivery = processTestDelivery(t, er, delivery.ID)
+	if delivery.Status != structs.DeliverySucceeded {
+		t.Errorf("Delivery status = %s, want succeeded: %s", delivery.Status, delivery.LastError)
+	}
+
+	checkSlackMessage(t, receiveStandInRequest(t, requests))
+}
+
+func TestSlackTextLength(t *testing.T) {
+	// Every character is escaped to four characters.
+	text := slackText(strings.Repeat("<", slackDescriptionLength), slackDescriptionLength)
+
+	if length := utf8.RuneCountInString(text); length > slackDescriptionLength {
+		t.Errorf("Length = %d, want at most %d", length, slackDescriptionLength)
+	}
+
+	if !strings.HasSuffix(text, "&lt;...") {
+		t.Errorf("Text ends with %q, want a whole escaped character", text[len(text)-10:])
+	}
+
+	if text := slackText("a & b", slackDescriptionLength); text != "a &amp; b" {
+		t.Errorf("slackText() = %q, want %q", text, "a &amp; b")
+	}
+
+	if text := slackText(strings.Repeat("é", 10), 5); text != "éé..." {
+		t.Errorf("slackText() = %q, want %q", text, "éé...")
+	}
+}
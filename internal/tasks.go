@@ -0,0 +1,256 @@
+package errorly
+
+import (
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"golang.org/x/xerrors"
+)
+
+// Interval between each run of the background tasks.
+const taskInterval = time.Minute
+
+// Interval between checking for inactive issues to resolve.
+const autoResolveInterval = time.Hour
+
+// systemUser is the author of comments and webhooks that are made by
+// Errorly instead of a user.
+var systemUser = &structs.User{
+	Name: "Errorly",
+}
+
+// RunTasks runs the background tasks every taskInterval until Errorly
+// has closed.
+func (er *Errorly) RunTasks() {
+	ticker := time.NewTicker(taskInterval)
+	defer ticker.Stop()
+
+	resolveTicker := time.NewTicker(autoResolveInterval)
+	defer resolveTicker.Stop()
+
+	for {
+		select {
+		case <-er.ctx.Done():
+			return
+		case <-ticker.C:
+			err := er.WakeSnoozedIssues()
+			if err != nil {
+				er.Logger.Error().Err(err).Msg("Failed to wake snoozed issues")
+			}
+		case <-resolveTicker.C:
+			err := er.ResolveInactiveIssues()
+			if err != nil {
+				er.Logger.Error().Err(err).Msg("Failed to resolve inactive issues")
+			}
+
+			err = er.PruneWebhookDeliveries()
+			if err != nil {
+				er.Logger.Error().Err(err).Msg("Failed to prune webhook deliveries")
+			}
+		}
+	}
+}
+
+// ResolveInactiveIssues marks active and open issues as resolved when they
+// have not occurred within the auto resolve period of their project.
+func (er *Errorly) ResolveInactiveIssues() (err error) {
+	projects := make([]structs.Project, 0)
+
+	err = er.Postgres.Model(&projects).
+		Where("(project.settings->>'auto_resolve_days')::int > 0").
+		Relation("Webhooks").
+		Select()
+	if err != nil {
+		return xerrors.Errorf("Failed to fetch projects: %w", err)
+	}
+
+	for i := range projects {
+		project := &projects[i]
+		inactiveSince := time.Now().UTC().AddDate(0, 0, -project.Settings.AutoResolveDays)
+
+		issues := make([]structs.IssueEntry, 0)
+
+		err = er.Postgres.Model(&issues).
+			Where("project_id = ?", project.ID).
+			WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+				q = q.WhereOr("type IS NULL").
+					WhereOr("type = ?", structs.EntryActive).
+					WhereOr("type = ?", structs.EntryOpen)
+
+				return q, nil
+			}).
+			Where("COALESCE(last_seen, created_at) < ?", inactiveSince).
+			Select()
+		if err != nil {
+			er.Logger.Warn().Err(err).Int64("project", project.ID).Msg("Failed to fetch inactive issues")
+
+			continue
+		}
+
+		for j := range issues {
+			err = er.MarkIssueStatus(project, &issues[j], structs.EntryResolved, systemUser)
+			if err != nil {
+				er.Logger.Warn().Err(err).Int64("issue", issues[j].ID).Msg("Failed to resolve inactive issue")
+			}
+		}
+	}
+
+	return nil
+}
+
+// WakeSnoozedIssues marks snoozed issues as active once any of their snooze
+// conditions have been met.
+func (er *Errorly) WakeSnoozedIssues() (err error) {
+	now := time.Now().UTC()
+	issues := make([]structs.IssueEntry, 0)
+
+	err = er.Postgres.Model(&issues).
+		Where("type = ?", structs.EntrySnoozed).
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			q = q.WhereOr("snoozed_until <= ?", now).
+				WhereOr("snooze_occurrences > 0 AND occurrences >= snooze_occurrences").
+				WhereOr("snooze_users > 0 AND users_affected >= snooze_users")
+
+			return q, nil
+		}).
+		Select()
+	if err != nil {
+		return xerrors.Errorf("Failed to fetch snoozed issues: %w", err)
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+
+		project := &structs.Project{}
+
+		err = er.Postgres.Model(project).
+			Where("project.id = ?", issue.ProjectID).
+			Relation("Webhooks").
+			Select()
+		if err != nil {
+			er.Logger.Warn().Err(err).Int64("issue", issue.ID).Msg("Failed to fetch project of snoozed issue")
+
+			continue
+		}
+
+		err = er.MarkIssueStatus(project, issue, structs.EntryActive, systemUser)
+		if err != nil {
+			er.Logger.Warn().Err(err).Int64("issue", issue.ID).Msg("Failed to wake snoozed issue")
+		}
+	}
+
+	return nil
+}
+
+// issueCounterColumns contains the project column counting the issues of
+// each EntryType. Invalid and snoozed issues are not counted.
+var issueCounterColumns = map[structs.EntryType]string{
+	structs.EntryActive:   "active_issues",
+	structs.EntryOpen:     "open_issues",
+	structs.EntryResolved: "resolved_issues",
+}
+
+// updateIssueCounters moves an issue between the issue counters of its
+// project. The counters are incremented in place so changes made to the
+// project since it was fetched are kept.
+func (er *Errorly) updateIssueCounters(project *structs.Project, from structs.EntryType,
+	to structs.EntryType) (err error) {
+	adjustIssueCounters(project, from, to)
+
+	fromColumn, toColumn := issueCounterColumns[from], issueCounterColumns[to]
+	if fromColumn == toColumn {
+		return nil
+	}
+
+	query := er.Postgres.Model((*structs.Project)(nil)).
+		Where("id = ?", project.ID)
+
+	if fromColumn != "" {
+		query = query.Set("? = COALESCE(?, 0) - 1", pg.Ident(fromColumn), pg.Ident(fromColumn))
+	}
+
+	if toColumn != "" {
+		query = query.Set("? = COALESCE(?, 0) + 1", pg.Ident(toColumn), pg.Ident(toColumn))
+	}
+
+	_, err = query.Update()
+	if err != nil {
+		return xerrors.Errorf("Failed to update project: %w", err)
+	}
+
+	return nil
+}
+
+// MarkIssueStatus changes the status of an issue on behalf of a user. The
+// project counters are updated, a system comment is made and the project
+// webhooks are notified.
+func (er *Errorly) MarkIssueStatus(project *structs.Project, issue *structs.IssueEntry,
+	markType structs.EntryType, author *structs.User) (err error) {
+	now := time.Now().UTC()
+	before := issueActivityValue(structs.ActionMarkStatus, issue)
+
+	// Only the counters are updated as the project may have been changed
+	// since it was fetched.
+	err = er.updateIssueCounters(project, issue.Type, markType)
+	if err != nil {
+		return err
+	}
+
+	issue.Type = markType
+
+	issue.SnoozedUntil = time.Time{}
+	issue.SnoozeOccurrences = 0
+	issue.SnoozeUsers = 0
+
+	issue.ResolvedInRelease = ""
+	issue.ResolvedInNextRelease = false
+
+	comment := &structs.Comment{
+		ID:          er.IDGen.GenerateID(),
+		IssueID:     issue.ID,
+		CreatedAt:   now,
+		CreatedByID: author.ID,
+		Type:        structs.IssueMarked,
+		IssueMarked: &markType,
+	}
+
+	_, err = er.Postgres.Model(comment).Insert()
+	if err != nil {
+		return xerrors.Errorf("Failed to insert comment: %w", err)
+	}
+
+	issue.CommentCount++
+	issue.LastModified = now
+
+	_, err = er.Postgres.Model(issue).
+		WherePK().
+		Update()
+	if err != nil {
+		return xerrors.Errorf("Failed to update issue: %w", err)
+	}
+
+	err = recordActivity(er, issue, author, structs.ActionMarkStatus, before,
+		issueActivityValue(structs.ActionMarkStatus, issue))
+	if err != nil {
+		return err
+	}
+
+	err = er.NotifySubscribers(project, issue, structs.IssueMarkStatus, nil, author)
+	if err != nil {
+		er.Logger.Warn().Err(err).Msg("Failed to notify subscribers")
+	}
+
+	err = er.HandleProjectWebhook(project, structs.WebhookMessage{
+		Type:    structs.IssueMarkStatus,
+		Project: project,
+		Issue:   issue,
+		Author:  author,
+	})
+	if err != nil {
+		er.Logger.Warn().Err(err).Msg("Failed to handle project webhook")
+	}
+
+	return nil
+}
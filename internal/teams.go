@@ -0,0 +1,126 @@
+package errorly
+
+import (
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+// Maximum length of a traceback shown in a teams message.
+const teamsTracebackLength = 500
+
+// priorityTeamsColors contains the Adaptive Card color used for the title
+// of issues of each priority.
+var priorityTeamsColors = map[structs.Priority]string{
+	structs.PriorityLow:      "Default",
+	structs.PriorityMedium:   "Accent",
+	structs.PriorityHigh:     "Warning",
+	structs.PriorityCritical: "Attention",
+}
+
+// ConvertErrorlyToTeamsWebhook handles converting a default payload to a
+// suitable microsoft teams Adaptive Card payload.
+func (er *Errorly) ConvertErrorlyToTeamsWebhook(payload structs.WebhookMessage) (bool, structs.TeamsMessage) {
+	var card *structs.AdaptiveCard
+
+	if payload.Type == structs.TestWebhook {
+		card = newAdaptiveCard(&structs.AdaptiveCardItem{
+			Type: "TextBlock",
+			Text: "Test Webhook",
+		})
+	} else {
+		summary, ok := er.summarizeWebhookMessage(payload)
+		if !ok {
+			return false, structs.TeamsMessage{}
+		}
+
+		card = newAdaptiveCard(&structs.AdaptiveCardItem{
+			Type:   "TextBlock",
+			Text:   summary.Title,
+			Size:   "Medium",
+			Weight: "Bolder",
+			Color:  priorityTeamsColors[payload.Issue.Priority],
+			Wrap:   true,
+		})
+
+		if payload.Author != nil {
+			card.Body = append(card.Body, &structs.AdaptiveCardItem{
+				Type:     "TextBlock",
+				Text:     payload.Author.Name,
+				IsSubtle: true,
+				Wrap:     true,
+			})
+		}
+
+		facts := make([]*structs.AdaptiveCardFact, 0, len(summary.Fields))
+		for _, field := range summary.Fields {
+			facts = append(facts, &structs.AdaptiveCardFact{
+				Title: field.Name,
+				Value: field.Value,
+			})
+		}
+
+		card.Body = append(card.Body, &structs.AdaptiveCardItem{
+			Type:  "FactSet",
+			Facts: facts,
+		})
+
+		if summary.Description != "" {
+			card.Body = append(card.Body, &structs.AdaptiveCardItem{
+				Type: "TextBlock",
+				Text: cutString(summary.Description, 2000),
+				Wrap: true,
+			})
+		}
+
+		if payload.Issue.Traceback != "" {
+			card.Body = append(card.Body, &structs.AdaptiveCardItem{
+				Type:     "TextBlock",
+				Text:     cutString(payload.Issue.Traceback, teamsTracebackLength),
+				FontType: "Monospace",
+				Wrap:     true,
+			})
+		}
+
+		card.Actions = []*structs.AdaptiveCardAction{
+			{
+				Type:  "Action.OpenUrl",
+				Title: "View issue",
+				URL:   summary.URL,
+			},
+		}
+	}
+
+	return true, structs.TeamsMessage{
+		Type: "message",
+		Attachments: []*structs.TeamsAttachment{
+			{
+				ContentType: "application/vnd.microsoft.card.adaptive",
+				Content:     card,
+			},
+		},
+	}
+}
+
+// newAdaptiveCard returns an Adaptive Card with the passed body.
+func newAdaptiveCard(body ...*structs.AdaptiveCardItem) *structs.AdaptiveCard {
+	return &structs.AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.2",
+		Body:    body,
+	}
+}
+
+// teamsFormatter sends microsoft teams Adaptive Cards.
+type teamsFormatter struct{}
+
+func (teamsFormatter) Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	ok, msg := er.ConvertErrorlyToTeamsWebhook(payload)
+	if !ok {
+		return nil, nil
+	}
+
+	return jsonRequest(msg)
+}
@@ -0,0 +1,389 @@
+package errorly
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+	"golang.org/x/xerrors"
+)
+
+// Maximum length of a webhook template.
+const maxWebhookTemplateLength = 16384
+
+// Maximum length of a rendered webhook template.
+const maxWebhookTemplateOutput = 65536
+
+// Content type used when a template webhook does not set one.
+const defaultTemplateContentType = "application/json"
+
+// Maximum depth of if, range and with actions in a webhook template.
+const maxWebhookTemplateNesting = 4
+
+// Maximum time a webhook template can take to render.
+const webhookTemplateTimeout = time.Second
+
+// errTemplateOutputTooLarge is returned when a template renders more than
+// maxWebhookTemplateOutput bytes.
+var errTemplateOutputTooLarge = xerrors.New("Rendered template is too large")
+
+// errTemplateTimeout is returned when a template takes longer than
+// webhookTemplateTimeout to render.
+var errTemplateTimeout = xerrors.New("Template took too long to render")
+
+// webhookMessageType is the type templates are rendered against.
+var webhookMessageType = reflect.TypeOf(structs.WebhookMessage{})
+
+// limitedBuffer is a buffer that errors once it grows past its limit or
+// is written to after its deadline.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	deadline time.Time
+}
+
+func (lb *limitedBuffer) Write(p []byte) (n int, err error) {
+	if lb.Len()+len(p) > lb.limit {
+		return 0, errTemplateOutputTooLarge
+	}
+
+	if !lb.deadline.IsZero() && time.Now().After(lb.deadline) {
+		return 0, errTemplateTimeout
+	}
+
+	return lb.Buffer.Write(p)
+}
+
+// webhookTemplateFuncs returns the functions available to webhook
+// templates for a payload.
+func (er *Errorly) webhookTemplateFuncs(payload structs.WebhookMessage) template.FuncMap {
+	return template.FuncMap{
+		// truncate shortens a string to at most length characters.
+		"truncate": func(length int, s string) string {
+			runes := []rune(s)
+			if length < 0 || len(runes) <= length {
+				return s
+			}
+
+			return string(runes[:length])
+		},
+		// json encodes a value as JSON, so strings are quoted and escaped.
+		"json": func(v interface{}) (string, error) {
+			res, err := json.Marshal(v)
+			if err != nil {
+				return "", err
+			}
+
+			return string(res), nil
+		},
+		// issueURL returns the link to the issue of the payload.
+		"issueURL": func() string {
+			if payload.Project == nil || payload.Issue == nil {
+				return ""
+			}
+
+			return fmt.Sprintf("%s/project/%d/issue/%d", er.Configuration.URL, payload.Project.ID, payload.Issue.ID)
+		},
+	}
+}
+
+// parseWebhookTemplate parses a webhook template. The returned error can
+// be shown to the user.
+func (er *Errorly) parseWebhookTemplate(text string) (tmpl *template.Template, err error) {
+	if text == "" {
+		return nil, xerrors.New("Template must not be empty")
+	}
+
+	if len(text) > maxWebhookTemplateLength {
+		return nil, xerrors.Errorf("Template must be at most %d characters", maxWebhookTemplateLength)
+	}
+
+	tmpl, err = template.New("webhook").
+		Funcs(er.webhookTemplateFuncs(structs.WebhookMessage{})).
+		Parse(text)
+	if err != nil {
+		return nil, xerrors.Errorf("Template is not valid: %v", err)
+	}
+
+	// Templates are rendered by the webhook workers so they must not be
+	// able to loop for longer than the payload allows.
+	if len(tmpl.Templates()) > 1 {
+		return nil, xerrors.New("Template is not valid: templates cannot be defined")
+	}
+
+	err = checkTemplateNode(tmpl.Tree.Root, webhookMessageType, 0)
+	if err != nil {
+		return nil, xerrors.Errorf("Template is not valid: %v", err)
+	}
+
+	return tmpl, nil
+}
+
+// checkTemplateNode checks the actions of a template node. Only slices,
+// arrays and maps of the payload can be ranged over, so the work done by a
+// template is limited by the size of the payload. dot is the type of dot
+// in the node, nil if it is not known.
+func checkTemplateNode(node parse.Node, dot reflect.Type, depth int) (err error) {
+	switch node := node.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+
+		for _, child := range node.Nodes {
+			err = checkTemplateNode(child, dot, depth)
+			if err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		return checkTemplateBranch(&node.BranchNode, dot, dot, depth)
+	case *parse.WithNode:
+		return checkTemplateBranch(&node.BranchNode, templatePipeType(node.Pipe, dot), dot, depth)
+	case *parse.RangeNode:
+		pipeType := templatePipeType(node.Pipe, dot)
+		if pipeType == nil {
+			return xerrors.Errorf("line %d: range must be over a list of the payload", node.Line)
+		}
+
+		switch pipeType.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+		default:
+			return xerrors.Errorf("line %d: range cannot be over %s", node.Line, pipeType.Kind())
+		}
+
+		return checkTemplateBranch(&node.BranchNode, pipeType.Elem(), dot, depth)
+	case *parse.TemplateNode:
+		return xerrors.Errorf("line %d: templates cannot be included", node.Line)
+	}
+
+	return nil
+}
+
+// checkTemplateBranch checks the lists of an if, range or with action.
+func checkTemplateBranch(node *parse.BranchNode, listDot reflect.Type, elseDot reflect.Type, depth int) (err error) {
+	if depth >= maxWebhookTemplateNesting {
+		return xerrors.Errorf("line %d: actions can be nested at most %d deep", node.Line, maxWebhookTemplateNesting)
+	}
+
+	err = checkTemplateNode(node.List, listDot, depth+1)
+	if err != nil {
+		return err
+	}
+
+	return checkTemplateNode(node.ElseList, elseDot, depth+1)
+}
+
+// templatePipeType returns the type of a pipeline that is a field of dot
+// or of the payload. Returns nil if the type is not known.
+func templatePipeType(pipe *parse.PipeNode, dot reflect.Type) reflect.Type {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return templateFieldType(dot, arg.Ident)
+	case *parse.VariableNode:
+		// Only $ is known, which is the payload.
+		if arg.Ident[0] == "$" {
+			return templateFieldType(webhookMessageType, arg.Ident[1:])
+		}
+	}
+
+	return nil
+}
+
+// templateFieldType returns the type of a chain of fields. Returns nil if
+// the type is not known.
+func templateFieldType(typ reflect.Type, fields []string) reflect.Type {
+	for _, name := range fields {
+		if typ == nil {
+			return nil
+		}
+
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			field, ok := typ.FieldByName(name)
+			if !ok {
+				return nil
+			}
+
+			typ = field.Type
+		case reflect.Map:
+			typ = typ.Elem()
+		default:
+			return nil
+		}
+	}
+
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+// validateTemplateContentType checks a template content type is a valid
+// media type. The returned error can be shown to the user.
+func validateTemplateContentType(contentType string) (err error) {
+	if contentType == "" {
+		return nil
+	}
+
+	_, _, err = mime.ParseMediaType(contentType)
+	if err != nil {
+		return xerrors.New("Template content type is not valid")
+	}
+
+	return nil
+}
+
+// RenderWebhookTemplate renders a webhook template against a payload. The
+// payload is passed through redactWebhookPayload first. Rendering fails
+// with errTemplateTimeout if it takes longer than webhookTemplateTimeout.
+func (er *Errorly) RenderWebhookTemplate(text string, payload structs.WebhookMessage) (body []byte, err error) {
+	tmpl, err := er.parseWebhookTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	payload = redactWebhookPayload(payload)
+
+	buf := &limitedBuffer{
+		limit:    maxWebhookTemplateOutput,
+		deadline: time.Now().Add(webhookTemplateTimeout),
+	}
+	tmpl = tmpl.Funcs(er.webhookTemplateFuncs(payload))
+
+	// Templates cannot be cancelled, so one that takes too long is left in
+	// the background until it next writes to the buffer.
+	done := make(chan error, 1)
+
+	go func() {
+		done <- tmpl.Execute(buf, payload)
+	}()
+
+	timer := time.NewTimer(webhookTemplateTimeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-done:
+	case <-timer.C:
+		return nil, errTemplateTimeout
+	}
+
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to render template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// sampleWebhookMessage returns an example payload of an event for a
+// project, used when previewing templates.
+func sampleWebhookMessage(project *structs.Project, author *structs.User,
+	eventType structs.WebhookEventType) structs.WebhookMessage {
+	now := time.Now().UTC()
+
+	content := "This is an example comment"
+	issue := &structs.IssueEntry{
+		ProjectID:   project.ID,
+		Type:        structs.EntryActive,
+		Priority:    structs.PriorityHigh,
+		Occurrences: 1,
+		Error:       "ZeroDivisionError: division by zero",
+		Function:    "calculate_average",
+		Checkpoint:  "stats.py:42",
+		Description: "An example issue",
+		Traceback: "Traceback (most recent call last):\n" +
+			"  File \"stats.py\", line 42, in calculate_average\n" +
+			"    return total / count\n" +
+			"ZeroDivisionError: division by zero",
+		Environment:   "production",
+		Release:       "1.0.0",
+		UsersAffected: 1,
+		LastModified:  now,
+		LastSeen:      now,
+		CreatedAt:     now,
+		CreatedByID:   author.ID,
+		CreatedBy:     author,
+	}
+
+	payload := structs.WebhookMessage{
+		Type:    eventType,
+		Project: project,
+		Issue:   issue,
+		Author:  author,
+	}
+
+	switch eventType {
+	case structs.TestWebhook:
+		payload.Project = nil
+		payload.Issue = nil
+		payload.Author = nil
+	case structs.IssueComment, structs.IssueCommentEdited, structs.IssueCommentDeleted:
+		payload.Comment = &structs.Comment{
+			IssueID:     issue.ID,
+			CreatedAt:   now,
+			CreatedBy:   author,
+			CreatedByID: author.ID,
+			Type:        structs.Message,
+			Content:     &content,
+		}
+	case structs.IssueStarred:
+		issue.Starred = true
+	case structs.IssueAssigned:
+		issue.Assignee = author
+		issue.AssigneeID = author.ID
+	case structs.IssueLocked:
+		issue.CommentsLocked = true
+	case structs.IssueMarkStatus:
+		issue.Type = structs.EntryResolved
+	case structs.IssueLabeled:
+		payload.AddedLabels = []*structs.Label{
+			{ProjectID: project.ID, Name: "bug", Color: "#d73a4a"},
+		}
+	}
+
+	return payload
+}
+
+// templateFormatter renders the template of the webhook.
+type templateFormatter struct{}
+
+func (templateFormatter) Format(er *Errorly, webhook *structs.Webhook, payload structs.WebhookMessage,
+	deliveryID int64, createdAt time.Time) (req *WebhookRequest, err error) {
+	body, err := er.RenderWebhookTemplate(webhook.Template, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := webhook.TemplateContentType
+	if contentType == "" {
+		contentType = defaultTemplateContentType
+	}
+
+	req = &WebhookRequest{
+		Header: http.Header{},
+		Body:   body,
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	return req, nil
+}
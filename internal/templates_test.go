@@ -0,0 +1,79 @@
+package errorly
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+)
+
+func TestParseWebhookTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		valid    bool
+	}{
+		{`{"error":{{json .Issue.Error}}}`, true},
+		{`{{range .Issue.Labels}}{{.Name}}{{end}}`, true},
+		{`{{range $i, $label := .Issue.Labels}}{{$label.Name}}{{end}}`, true},
+		{`{{with .Issue}}{{range .Labels}}{{.Name}}{{end}}{{end}}`, true},
+		{`{{range .AddedLabels}}{{range $.Issue.Labels}}{{.Name}}{{end}}{{end}}`, true},
+		{`{{if .Issue}}{{if .Issue}}{{if .Issue}}{{if .Issue}}x{{end}}{{end}}{{end}}{{end}}`, true},
+		{`{{range 300000000}}{{end}}`, false},
+		{`{{range .Issue.Occurrences}}{{end}}`, false},
+		{`{{range $.Issue.Occurrences}}{{end}}`, false},
+		{`{{$n := 300000000}}{{range $n}}{{end}}`, false},
+		{`{{range json .}}{{end}}`, false},
+		{`{{range .Issue.Labels}}{{range .Name}}{{end}}{{end}}`, false},
+		{`{{if .Issue}}{{if .Issue}}{{if .Issue}}{{if .Issue}}{{if .Issue}}x{{end}}{{end}}{{end}}{{end}}{{end}}`, false},
+		{`{{define "loop"}}{{template "loop"}}{{end}}{{template "loop"}}`, false},
+		{`{{template "webhook"}}`, false},
+	}
+
+	er := newTestErrorly(t)
+
+	for _, test := range tests {
+		_, err := er.parseWebhookTemplate(test.template)
+		if valid := err == nil; valid != test.valid {
+			t.Errorf("parseWebhookTemplate(%q) error = %v, want valid %t", test.template, err, test.valid)
+		}
+	}
+}
+
+func TestRenderWebhookTemplateTimeout(t *testing.T) {
+	er := newTestErrorly(t)
+
+	payload := testWebhookMessage(structs.IssueCreate)
+	for i := 0; i < 1000; i++ {
+		payload.Issue.Labels = append(payload.Issue.Labels, &structs.Label{Name: "bug"})
+	}
+
+	loop := strings.Repeat(`{{range $.Issue.Labels}}`, maxWebhookTemplateNesting) +
+		strings.Repeat(`{{end}}`, maxWebhookTemplateNesting)
+
+	_, err := er.RenderWebhookTemplate(loop, payload)
+	if !errors.Is(err, errTemplateTimeout) {
+		t.Fatalf("RenderWebhookTemplate() error = %v, want %v", err, errTemplateTimeout)
+	}
+
+	webhook := &structs.Webhook{
+		URL:      "https://errorly.example/webhook",
+		Type:     structs.TemplateWebhook,
+		Template: loop,
+	}
+	attempt := &structs.WebhookDeliveryAttempt{}
+
+	ok, err := er.DoWebhook(webhook, payload, time.Now(), attempt)
+	if ok || err == nil {
+		t.Fatalf("DoWebhook() = %t, %v, want an error", ok, err)
+	}
+
+	if isRetryableWebhookError(err) {
+		t.Errorf("DoWebhook() error %v is retryable", err)
+	}
+
+	if attempt.Error == "" {
+		t.Error("Attempt error is not set")
+	}
+}
@@ -1,11 +1,26 @@
 package errorly
 
 import (
+	"encoding/base64"
 	"encoding/binary"
+	"strconv"
+	"time"
 
 	"github.com/btcsuite/btcutil/base58"
+	"golang.org/x/xerrors"
 )
 
+// ErrInvalidCursor is raised when a cursor cannot be decoded or was made
+// for a different ordering.
+var ErrInvalidCursor = xerrors.New("Invalid cursor passed")
+
+// pageCursor is the structure of an opaque pagination cursor. It holds
+// the keys the results were ordered by and the values of the last result.
+type pageCursor struct {
+	Keys   []string `json:"k"`
+	Values []string `json:"v"`
+}
+
 func idFromUInt64(i uint64) string {
 	buf := make([]byte, binary.MaxVarintLen64)
 	binary.LittleEndian.PutUint64(buf, i)
@@ -16,3 +31,72 @@ func idFromUInt64(i uint64) string {
 func uint64FromID(id string) uint64 {
 	return binary.LittleEndian.Uint64(base58.Decode(id))
 }
+
+// encodeCursor creates an opaque cursor from the ordering keys and the
+// values of the last result.
+func encodeCursor(keys []string, values []string) string {
+	res, err := json.Marshal(pageCursor{
+		Keys:   keys,
+		Values: values,
+	})
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(res)
+}
+
+// parseCursorValue converts a cursor value to the type of the column its
+// key orders by, so a tampered cursor cannot cause a database error.
+func parseCursorValue(key string, value string) (interface{}, error) {
+	switch key {
+	case "starred":
+		return strconv.ParseBool(value)
+	case "id", "type", "occurrences", "assignee_id", "comment_count", "priority", "reactions":
+		return strconv.ParseInt(value, 10, 64)
+	case "last_modified", "last_seen", "created_at":
+		return time.Parse(time.RFC3339Nano, value)
+	}
+
+	return value, nil
+}
+
+// decodeCursor returns the values of a cursor. An error is returned if the
+// cursor was not created with the same ordering keys or a value is not
+// valid for its key.
+func decodeCursor(cursor string, keys []string) (values []interface{}, err error) {
+	res, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	_cursor := pageCursor{}
+
+	err = json.Unmarshal(res, &_cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if len(_cursor.Keys) != len(keys) || len(_cursor.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, key := range keys {
+		if _cursor.Keys[i] != key {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	values = make([]interface{}, 0, len(keys))
+
+	for i, key := range keys {
+		value, err := parseCursorValue(key, _cursor.Values[i])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
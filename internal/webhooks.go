@@ -0,0 +1,448 @@
+package errorly
+
+import (
+	"errors"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TheRockettek/Errorly-Web/structs"
+	"github.com/go-pg/pg/v10"
+	"golang.org/x/xerrors"
+)
+
+const (
+	defaultWebhookWorkers     = 4
+	defaultWebhookTimeout     = 10 // seconds
+	defaultWebhookMaxAttempts = 8
+)
+
+// Interval between idle webhook workers checking for due deliveries.
+const webhookPollInterval = time.Second * 5
+
+// Time a claimed delivery is hidden from other workers. If a worker stops
+// before finishing an attempt, the delivery is retried after this.
+const webhookLease = time.Minute * 5
+
+// Delay before the first retry of a delivery. This doubles every attempt
+// up to webhookMaxBackoff.
+const (
+	webhookBaseBackoff = time.Second * 10
+	webhookMaxBackoff  = time.Hour
+)
+
+// Number of failed deliveries in a row before a webhook is disabled.
+const webhookMaxFailures = 5
+
+// Time completed deliveries are kept for before being pruned.
+const webhookDeliveryRetention = time.Hour * 24 * 7
+
+// Time the previous secret of a webhook is still used to sign deliveries
+// after the secret is changed.
+const webhookSecretRotationPeriod = time.Hour * 24
+
+// webhookSecrets returns the secrets deliveries to a webhook should be
+// signed with. The previous secret is included until it expires.
+func webhookSecrets(webhook *structs.Webhook) (secrets []string) {
+	secrets = make([]string, 0, 2)
+
+	if webhook.Secret != "" {
+		secrets = append(secrets, webhook.Secret)
+	}
+
+	if webhook.PreviousSecret != "" && time.Now().UTC().Before(webhook.PreviousSecretExpiresAt) {
+		secrets = append(secrets, webhook.PreviousSecret)
+	}
+
+	return secrets
+}
+
+// WebhookStatusError is returned when a webhook responds with a non 2xx
+// status code.
+type WebhookStatusError struct {
+	StatusCode int
+}
+
+func (wse *WebhookStatusError) Error() string {
+	return "webhook responded with status " + strconv.Itoa(wse.StatusCode)
+}
+
+// isRetryableWebhookError returns if a delivery should be retried. Only
+// network errors and 5xx responses are retried.
+func isRetryableWebhookError(err error) bool {
+	var statusErr *WebhookStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+
+	var urlErr *url.Error
+
+	return errors.As(err, &urlErr)
+}
+
+// parseWebhookURL parses the url of a webhook. Only absolute http and
+// https urls are allowed.
+func parseWebhookURL(rawURL string) (webhookURL *url.URL, err error) {
+	webhookURL, err = url.Parse(rawURL)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to parse url: %w", err)
+	}
+
+	if (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return nil, xerrors.Errorf("Unsupported url: '%s'", rawURL)
+	}
+
+	return webhookURL, nil
+}
+
+// webhookBackoff returns the delay before retrying a delivery that has
+// been attempted the passed number of times. Half of the delay is random
+// so retries to the same endpoint are spread out.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookMaxBackoff
+	if attempts <= 16 {
+		backoff = webhookBaseBackoff << uint(attempts-1)
+		if backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// webhookMatches returns if a webhook should receive a payload based on
+// the events it is subscribed to and its filters. Filters are only checked
+// for payloads with an issue.
+func (er *Errorly) webhookMatches(webhook *structs.Webhook, payload structs.WebhookMessage) (ok bool, err error) {
+	if !webhook.Events.Has(payload.Type) {
+		return false, nil
+	}
+
+	issue := payload.Issue
+	if issue == nil {
+		return true, nil
+	}
+
+	filters := webhook.Filters
+
+	if len(filters.Environments) > 0 {
+		matched := false
+
+		for _, environment := range filters.Environments {
+			if environment == issue.Environment {
+				matched = true
+			}
+		}
+
+		if !matched {
+			return false, nil
+		}
+	}
+
+	if issue.Priority < filters.MinPriority {
+		return false, nil
+	}
+
+	if len(filters.Labels) == 0 && filters.Query == "" {
+		return true, nil
+	}
+
+	// Labels and queries are checked against the stored issue so they
+	// behave the same as when searching for issues.
+	query := er.Postgres.Model((*structs.IssueEntry)(nil)).
+		Where("issue_entry.id = ?", issue.ID)
+
+	if len(filters.Labels) > 0 {
+		labels := make([]string, 0, len(filters.Labels))
+		for _, label := range filters.Labels {
+			labels = append(labels, strings.ToLower(label))
+		}
+
+		query = query.Where("issue_entry.id IN (SELECT issue_label.issue_id FROM issue_labels AS issue_label "+
+			"JOIN labels AS label ON label.id = issue_label.label_id "+
+			"WHERE label.project_id = issue_entry.project_id AND lower(label.name) IN (?))", pg.In(labels))
+	}
+
+	if filters.Query != "" {
+		search, err := parseIssueQuery(filters.Query, 0)
+		if err != nil {
+			return false, xerrors.Errorf("Failed to parse query: %w", err)
+		}
+
+		query = search.Apply(query)
+	}
+
+	ok, err = query.Exists()
+	if err != nil {
+		return false, xerrors.Errorf("Failed to filter issue: %w", err)
+	}
+
+	return ok, nil
+}
+
+// redactUser returns a copy of a user without values that are not shown
+// in the API, such as integration tokens.
+func redactUser(user *structs.User) *structs.User {
+	if user == nil {
+		return nil
+	}
+
+	return &structs.User{
+		ID:          user.ID,
+		Name:        user.Name,
+		Avatar:      user.Avatar,
+		CreatedAt:   user.CreatedAt,
+		ProjectID:   user.ProjectID,
+		CreatedByID: user.CreatedByID,
+		Integration: user.Integration,
+	}
+}
+
+// redactWebhookPayload returns a copy of a payload that is safe to store
+// and send to webhooks. The integrations, webhooks and invites of the
+// project are removed and users are copied with redactUser so their
+// secrets cannot be included.
+func redactWebhookPayload(payload structs.WebhookMessage) structs.WebhookMessage {
+	payload.Author = redactUser(payload.Author)
+
+	if payload.Project != nil {
+		project := *payload.Project
+		project.CreatedBy = redactUser(project.CreatedBy)
+		project.Integrations = nil
+		project.Webhooks = nil
+		project.InviteCodes = nil
+		project.Issues = nil
+		payload.Project = &project
+	}
+
+	if payload.Issue != nil {
+		issue := *payload.Issue
+		issue.CreatedBy = redactUser(issue.CreatedBy)
+		issue.Assignee = redactUser(issue.Assignee)
+		issue.Comments = nil
+		payload.Issue = &issue
+	}
+
+	if payload.Comment != nil {
+		comment := *payload.Comment
+		comment.CreatedBy = redactUser(comment.CreatedBy)
+		comment.Edits = nil
+		payload.Comment = &comment
+	}
+
+	return payload
+}
+
+// EnqueueWebhook queues a payload to be sent to a webhook. The payload is
+// passed through redactWebhookPayload before it is stored.
+func (er *Errorly) EnqueueWebhook(webhook *structs.Webhook,
+	payload structs.WebhookMessage) (delivery *structs.WebhookDelivery, err error) {
+	now := time.Now().UTC()
+
+	delivery = &structs.WebhookDelivery{
+		ID:            er.IDGen.GenerateID(),
+		WebhookID:     webhook.ID,
+		ProjectID:     webhook.ProjectID,
+		CreatedAt:     now,
+		Payload:       redactWebhookPayload(payload),
+		Status:        structs.DeliveryPending,
+		NextAttemptAt: now,
+	}
+
+	_, err = er.Postgres.Model(delivery).Insert()
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to insert delivery: %w", err)
+	}
+
+	// Wake up a worker if one is idle, otherwise it will be picked up
+	// on the next poll.
+	select {
+	case er.webhookWake <- struct{}{}:
+	default:
+	}
+
+	return delivery, nil
+}
+
+// RunWebhookWorkers starts the workers that send queued webhook deliveries.
+func (er *Errorly) RunWebhookWorkers() {
+	rand.Seed(time.Now().UnixNano())
+
+	for i := 0; i < er.Configuration.Webhooks.Workers; i++ {
+		go er.webhookWorker()
+	}
+}
+
+func (er *Errorly) webhookWorker() {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := er.ProcessWebhookDelivery()
+		if err != nil {
+			er.Logger.Error().Err(err).Msg("Failed to process webhook delivery")
+		}
+
+		// Keep working through the queue while there are due deliveries.
+		if claimed && err == nil {
+			continue
+		}
+
+		select {
+		case <-er.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-er.webhookWake:
+		}
+	}
+}
+
+// claimWebhookDelivery claims the next due delivery so no other worker
+// will attempt it. Returns nil if there are no due deliveries.
+func (er *Errorly) claimWebhookDelivery() (delivery *structs.WebhookDelivery, err error) {
+	now := time.Now().UTC()
+	delivery = &structs.WebhookDelivery{}
+
+	res, err := er.Postgres.Model(delivery).
+		Set("next_attempt_at = ?", now.Add(webhookLease)).
+		Where("id = (SELECT id FROM ?TableName WHERE status = ? AND next_attempt_at <= ? "+
+			"ORDER BY next_attempt_at LIMIT 1 FOR UPDATE SKIP LOCKED)", structs.DeliveryPending, now).
+		Returning("*").
+		Update()
+	if err != nil {
+		if errors.Is(err, pg.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, xerrors.Errorf("Failed to claim delivery: %w", err)
+	}
+
+	if res.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	return delivery, nil
+}
+
+// ProcessWebhookDelivery attempts the next due delivery. Returns false if
+// there were no due deliveries.
+func (er *Errorly) ProcessWebhookDelivery() (claimed bool, err error) {
+	delivery, err := er.claimWebhookDelivery()
+	if err != nil || delivery == nil {
+		return false, err
+	}
+
+	webhook := &structs.Webhook{}
+
+	err = er.Postgres.Model(webhook).
+		Where("id = ?", delivery.WebhookID).
+		Select()
+	if err != nil && !errors.Is(err, pg.ErrNoRows) {
+		return true, xerrors.Errorf("Failed to fetch webhook: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	switch {
+	case err != nil:
+		delivery.Status = structs.DeliveryFailed
+		delivery.LastError = "webhook no longer exists"
+		delivery.CompletedAt = now
+	case !webhook.Active:
+		delivery.Status = structs.DeliveryFailed
+		delivery.LastError = "webhook is disabled"
+		delivery.CompletedAt = now
+	default:
+		delivery.Attempts++
+
+		attempt := &structs.WebhookDeliveryAttempt{
+			ID:         er.IDGen.GenerateID(),
+			DeliveryID: delivery.ID,
+			WebhookID:  webhook.ID,
+			ProjectID:  webhook.ProjectID,
+			CreatedAt:  time.Now().UTC(),
+		}
+
+		ok, err := er.DoWebhook(webhook, delivery.Payload, delivery.CreatedAt, attempt)
+
+		// Payloads that are not supported by the webhook type are not sent
+		// so there is no attempt to record.
+		if attempt.RequestURL != "" || attempt.Error != "" {
+			_, insertErr := er.Postgres.Model(attempt).Insert()
+			if insertErr != nil {
+				er.Logger.Warn().Err(insertErr).Msg("Failed to record webhook delivery attempt")
+			}
+		}
+
+		if ok {
+			delivery.Status = structs.DeliverySucceeded
+			delivery.LastError = ""
+			delivery.CompletedAt = time.Now().UTC()
+
+			webhook.Failures = 0
+		} else {
+			if err != nil {
+				delivery.LastError = err.Error()
+			}
+
+			if isRetryableWebhookError(err) && delivery.Attempts < er.Configuration.Webhooks.MaxAttempts {
+				delivery.NextAttemptAt = time.Now().UTC().Add(webhookBackoff(delivery.Attempts))
+			} else {
+				delivery.Status = structs.DeliveryFailed
+				delivery.CompletedAt = time.Now().UTC()
+
+				webhook.Failures++
+				if webhook.Failures >= webhookMaxFailures {
+					webhook.Active = false
+				}
+			}
+		}
+
+		_, err = er.Postgres.Model(webhook).
+			Column("failures", "active").
+			WherePK().
+			Update()
+		if err != nil {
+			return true, xerrors.Errorf("Failed to update webhook: %w", err)
+		}
+	}
+
+	_, err = er.Postgres.Model(delivery).
+		WherePK().
+		Update()
+	if err != nil {
+		return true, xerrors.Errorf("Failed to update delivery: %w", err)
+	}
+
+	return true, nil
+}
+
+// PruneWebhookDeliveries removes completed deliveries older than the
+// delivery retention period.
+func (er *Errorly) PruneWebhookDeliveries() (err error) {
+	cutoff := time.Now().UTC().Add(-webhookDeliveryRetention)
+
+	expired := er.Postgres.Model((*structs.WebhookDelivery)(nil)).
+		Column("id").
+		Where("status != ?", structs.DeliveryPending).
+		Where("completed_at < ?", cutoff)
+
+	_, err = er.Postgres.Model(&structs.WebhookDeliveryAttempt{}).
+		Where("delivery_id IN (?)", expired).
+		Delete()
+	if err != nil {
+		return xerrors.Errorf("Failed to prune delivery attempts: %w", err)
+	}
+
+	_, err = er.Postgres.Model(&structs.WebhookDelivery{}).
+		Where("status != ?", structs.DeliveryPending).
+		Where("completed_at < ?", cutoff).
+		Delete()
+	if err != nil {
+		return xerrors.Errorf("Failed to prune deliveries: %w", err)
+	}
+
+	return nil
+}
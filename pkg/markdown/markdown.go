@@ -0,0 +1,241 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingRegex     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	ruleRegex        = regexp.MustCompile(`^ {0,3}(?:(?:- *){3,}|(?:\* *){3,}|(?:_ *){3,})$`)
+	unorderedRegex   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRegex     = regexp.MustCompile(`^\s*\d{1,9}[.)]\s+(.*)$`)
+	quoteRegex       = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	fenceRegex       = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+-]*)")
+	languageRegex    = regexp.MustCompile(`^[\w+-]+$`)
+	codeSpanRegex    = regexp.MustCompile("`([^`]+)`")
+	linkRegex        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	autolinkRegex    = regexp.MustCompile(`https?://[^\s<>"']+[^\s<>"'.,;:!?)\]]`)
+	boldRegex        = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	italicRegex      = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*|\b_([^_\s](?:[^_]*[^_\s])?)_\b`)
+	strikeRegex      = regexp.MustCompile(`~~(.+?)~~`)
+	placeholderRegex = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// allowedSchemes are the link prefixes that can be rendered. Anything else,
+// such as javascript: links, is rendered as plain text.
+var allowedSchemes = []string{"http://", "https://", "mailto:", "/", "#"}
+
+// Render converts markdown into HTML. Only a safe subset of markdown is
+// supported and any HTML in the source is escaped, so the output can be
+// displayed without further sanitizing.
+func Render(src string) string {
+	var out strings.Builder
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case fenceRegex.MatchString(line):
+			i = renderCodeBlock(&out, lines, i)
+		case headingRegex.MatchString(line):
+			match := headingRegex.FindStringSubmatch(line)
+			level := strconv.Itoa(len(match[1]))
+
+			out.WriteString("<h" + level + ">" + renderInline(match[2]) + "</h" + level + ">\n")
+
+			i++
+		case ruleRegex.MatchString(line):
+			out.WriteString("<hr>\n")
+
+			i++
+		case quoteRegex.MatchString(line):
+			quoted := make([]string, 0)
+
+			for ; i < len(lines) && quoteRegex.MatchString(lines[i]); i++ {
+				quoted = append(quoted, quoteRegex.FindStringSubmatch(lines[i])[1])
+			}
+
+			out.WriteString("<blockquote>\n" + Render(strings.Join(quoted, "\n")) + "</blockquote>\n")
+		case unorderedRegex.MatchString(line):
+			i = renderList(&out, lines, i, "ul", unorderedRegex)
+		case orderedRegex.MatchString(line):
+			i = renderList(&out, lines, i, "ol", orderedRegex)
+		default:
+			paragraph := make([]string, 0)
+
+			for ; i < len(lines) && !isBlockStart(lines[i]); i++ {
+				paragraph = append(paragraph, renderInline(strings.TrimSpace(lines[i])))
+			}
+
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+		}
+	}
+
+	return out.String()
+}
+
+// isBlockStart returns if a line ends a paragraph.
+func isBlockStart(line string) bool {
+	return strings.TrimSpace(line) == "" ||
+		fenceRegex.MatchString(line) ||
+		headingRegex.MatchString(line) ||
+		ruleRegex.MatchString(line) ||
+		quoteRegex.MatchString(line) ||
+		unorderedRegex.MatchString(line) ||
+		orderedRegex.MatchString(line)
+}
+
+// renderCodeBlock writes the fenced code block starting at line i and
+// returns the index of the line after it. Unclosed blocks run until the
+// end of the source.
+func renderCodeBlock(out *strings.Builder, lines []string, i int) int {
+	match := fenceRegex.FindStringSubmatch(lines[i])
+	fence, language := match[1], match[2]
+
+	code := make([]string, 0)
+
+	for i++; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+			i++
+
+			break
+		}
+
+		code = append(code, html.EscapeString(lines[i]))
+	}
+
+	if language != "" && languageRegex.MatchString(language) {
+		out.WriteString(`<pre><code class="language-` + language + `">`)
+	} else {
+		out.WriteString("<pre><code>")
+	}
+
+	out.WriteString(strings.Join(code, "\n") + "</code></pre>\n")
+
+	return i
+}
+
+// renderList writes the list starting at line i and returns the index of
+// the line after it.
+func renderList(out *strings.Builder, lines []string, i int, tag string, itemRegex *regexp.Regexp) int {
+	out.WriteString("<" + tag + ">\n")
+
+	for ; i < len(lines) && itemRegex.MatchString(lines[i]); i++ {
+		out.WriteString("<li>" + renderInline(itemRegex.FindStringSubmatch(lines[i])[1]) + "</li>\n")
+	}
+
+	out.WriteString("</" + tag + ">\n")
+
+	return i
+}
+
+// renderInline converts inline markdown such as emphasis, code spans and
+// links into HTML.
+func renderInline(src string) string {
+	// Code spans and links are replaced with placeholders so their contents
+	// are not affected by emphasis.
+	rendered := make([]string, 0)
+
+	placeholder := func(s string) string {
+		rendered = append(rendered, s)
+
+		return "\x00" + strconv.Itoa(len(rendered)-1) + "\x00"
+	}
+
+	src = strings.ReplaceAll(src, "\x00", "")
+
+	src = codeSpanRegex.ReplaceAllStringFunc(src, func(s string) string {
+		return placeholder("<code>" + html.EscapeString(codeSpanRegex.FindStringSubmatch(s)[1]) + "</code>")
+	})
+
+	src = linkRegex.ReplaceAllStringFunc(src, func(s string) string {
+		match := linkRegex.FindStringSubmatch(s)
+		if !isSafeURL(match[2]) {
+			return s
+		}
+
+		return placeholder(link(match[2], renderEmphasis(html.EscapeString(match[1]))))
+	})
+
+	src = autolinkRegex.ReplaceAllStringFunc(src, func(s string) string {
+		return placeholder(link(s, html.EscapeString(s)))
+	})
+
+	src = renderEmphasis(html.EscapeString(src))
+
+	return placeholderRegex.ReplaceAllStringFunc(src, func(s string) string {
+		index, _ := strconv.Atoi(placeholderRegex.FindStringSubmatch(s)[1])
+
+		return rendered[index]
+	})
+}
+
+// renderEmphasis converts bold, italic and strikethrough markdown in
+// escaped text into HTML.
+func renderEmphasis(src string) string {
+	src = boldRegex.ReplaceAllString(src, "<strong>$1$2</strong>")
+	src = italicRegex.ReplaceAllString(src, "<em>$1$2</em>")
+	src = strikeRegex.ReplaceAllString(src, "<del>$1</del>")
+
+	return src
+}
+
+// link returns an anchor to the url with the already escaped text.
+func link(url string, text string) string {
+	return `<a href="` + html.EscapeString(url) + `" rel="nofollow noopener noreferrer">` + text + `</a>`
+}
+
+// isSafeURL returns if a url uses an allowed scheme.
+func isSafeURL(url string) bool {
+	lower := strings.ToLower(url)
+
+	// Protocol relative urls such as //example.com are not allowed as they
+	// could be mistaken for relative paths. Browsers treat a backslash as a
+	// slash so /\example.com is protocol relative too.
+	if len(lower) > 1 && lower[0] == '/' && (lower[1] == '/' || lower[1] == '\\') {
+		return false
+	}
+
+	for _, scheme := range allowedSchemes {
+		if strings.HasPrefix(lower, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ToDiscord converts markdown into the subset supported by discord.
+// Headings become bold text and horizontal rules are removed.
+func ToDiscord(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	inCode := false
+
+	for i, line := range lines {
+		if fenceRegex.MatchString(line) {
+			inCode = !inCode
+
+			continue
+		}
+
+		if inCode {
+			continue
+		}
+
+		switch {
+		case headingRegex.MatchString(line):
+			lines[i] = "**" + headingRegex.FindStringSubmatch(line)[2] + "**"
+		case ruleRegex.MatchString(line):
+			lines[i] = ""
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
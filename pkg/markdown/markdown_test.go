@@ -0,0 +1,97 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		safe bool
+	}{
+		{"https://example.com", true},
+		{"http://example.com/path?q=1", true},
+		{"mailto:user@example.com", true},
+		{"/project/1/issue/2", true},
+		{"#heading", true},
+		{"/", true},
+		{"javascript:alert(1)", false},
+		{"JaVaScRiPt:alert(1)", false},
+		{"vbscript:msgbox(1)", false},
+		{"data:text/html,<script>alert(1)</script>", false},
+		{"&#106;avascript:alert(1)", false},
+		{"&#x6A;avascript:alert(1)", false},
+		{"java&#x09;script:alert(1)", false},
+		{"//evil.com", false},
+		{"/\\evil.com", false},
+		{"\\\\evil.com", false},
+		{"evil.com", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if safe := isSafeURL(test.url); safe != test.safe {
+			t.Errorf("isSafeURL(%q) = %t, want %t", test.url, safe, test.safe)
+		}
+	}
+}
+
+func TestRenderLinks(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"[x](https://example.com)", `<a href="https://example.com" rel="nofollow noopener noreferrer">x</a>`},
+		{"[x](/project/1)", `<a href="/project/1" rel="nofollow noopener noreferrer">x</a>`},
+		{"[x](javascript:alert(1))", "[x](javascript:alert(1))"},
+		{"[x](JaVaScRiPt:alert(1))", "[x](JaVaScRiPt:alert(1))"},
+		{"[x](&#106;avascript:alert(1))", "[x](&amp;#106;avascript:alert(1))"},
+		{"[x](//evil.com)", "[x](//evil.com)"},
+		{"[x](/\\evil.com)", "[x](/\\evil.com)"},
+	}
+
+	for _, test := range tests {
+		if got := Render(test.src); !strings.Contains(got, test.want) {
+			t.Errorf("Render(%q) = %q, want it to contain %q", test.src, got, test.want)
+		}
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	tests := []string{
+		"<script>alert(1)</script>",
+		`<img src=x onerror="alert(1)">`,
+		`<a href="javascript:alert(1)">x</a>`,
+		`<svg/onload=alert(1)>`,
+		`[x](https://example.com/"onmouseover="alert(1))`,
+		`https://example.com/"onmouseover="alert(1)`,
+		"```\n</code><script>alert(1)</script>\n```",
+		"```js\"><script>alert(1)</script>\ncode\n```",
+		"`<script>alert(1)</script>`",
+		"# <script>alert(1)</script>",
+		"> <img src=x onerror=alert(1)>",
+		"- <b onclick=alert(1)>x</b>",
+	}
+
+	forbidden := []string{"<script", "<img", "<svg", "<b ", `"onmouseover`, `"javascript:`, "onerror=\""}
+
+	for _, src := range tests {
+		got := Render(src)
+
+		for _, s := range forbidden {
+			if strings.Contains(got, s) {
+				t.Errorf("Render(%q) = %q, must not contain %q", src, got, s)
+			}
+		}
+	}
+}
+
+func TestToDiscord(t *testing.T) {
+	src := "# Heading\n---\n```\n# not a heading\n```"
+	want := "**Heading**\n\n```\n# not a heading\n```"
+
+	if got := ToDiscord(src); got != want {
+		t.Errorf("ToDiscord(%q) = %q, want %q", src, got, want)
+	}
+}
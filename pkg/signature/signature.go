@@ -0,0 +1,160 @@
+// Package signature signs and verifies Errorly webhook deliveries.
+//
+// Every delivery is sent with an X-Errorly-Signature header such as
+//
+//	t=1617062400,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+//
+// where t is the unix timestamp the delivery was signed at and v1 is the
+// hex encoded HMAC-SHA256 of "{t}.{delivery id}.{body}" using the webhook
+// secret. While a secret is being rotated the header contains a v1
+// signature for both the new and previous secret. The delivery id is sent
+// in the X-Errorly-Delivery header.
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// SignatureHeader is the header the signature is sent in.
+	SignatureHeader = "X-Errorly-Signature"
+	// DeliveryHeader is the header the delivery id is sent in.
+	DeliveryHeader = "X-Errorly-Delivery"
+)
+
+// DefaultTolerance is the recommended maximum age of a signature. Requests
+// signed longer ago than this should be treated as replays.
+const DefaultTolerance = time.Minute * 5
+
+var (
+	// ErrInvalidHeader is returned when the signature header is malformed.
+	ErrInvalidHeader = xerrors.New("Signature header is not valid")
+	// ErrNoSignatures is returned when the header has no v1 signatures.
+	ErrNoSignatures = xerrors.New("Signature header has no v1 signatures")
+	// ErrExpired is returned when the signature timestamp is outside of the
+	// tolerance.
+	ErrExpired = xerrors.New("Signature timestamp is outside of the tolerance")
+	// ErrMismatch is returned when no signature matches the secret.
+	ErrMismatch = xerrors.New("Signature does not match")
+)
+
+// Sign returns the hex encoded v1 signature of a delivery.
+func Sign(secret string, timestamp time.Time, deliveryID string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write([]byte(deliveryID))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Format returns the signature header of a delivery signed with every
+// passed secret. Empty secrets are ignored.
+func Format(timestamp time.Time, deliveryID string, body []byte, secrets ...string) string {
+	parts := []string{"t=" + strconv.FormatInt(timestamp.Unix(), 10)}
+
+	for _, secret := range secrets {
+		if secret != "" {
+			parts = append(parts, "v1="+Sign(secret, timestamp, deliveryID, body))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// Parse returns the timestamp and v1 signatures of a signature header.
+// Unknown schemes are ignored so newer versions can be added.
+func Parse(header string) (timestamp time.Time, signatures []string, err error) {
+	signatures = make([]string, 0)
+
+	var hasTimestamp bool
+
+	for _, part := range strings.Split(header, ",") {
+		pair := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(pair) != 2 {
+			return timestamp, nil, ErrInvalidHeader
+		}
+
+		switch pair[0] {
+		case "t":
+			unix, err := strconv.ParseInt(pair[1], 10, 64)
+			if err != nil {
+				return timestamp, nil, ErrInvalidHeader
+			}
+
+			timestamp = time.Unix(unix, 0)
+			hasTimestamp = true
+		case "v1":
+			signatures = append(signatures, pair[1])
+		}
+	}
+
+	if !hasTimestamp {
+		return timestamp, nil, ErrInvalidHeader
+	}
+
+	if len(signatures) == 0 {
+		return timestamp, nil, ErrNoSignatures
+	}
+
+	return timestamp, signatures, nil
+}
+
+// Verify checks a signature header against the delivery id, body and
+// secret. Signatures older or newer than the tolerance are rejected. A
+// tolerance of 0 disables the check which is not recommended.
+func Verify(header string, deliveryID string, body []byte, secret string, tolerance time.Duration) (err error) {
+	timestamp, signatures, err := Parse(header)
+	if err != nil {
+		return err
+	}
+
+	if tolerance > 0 {
+		age := time.Since(timestamp)
+		if age > tolerance || age < -tolerance {
+			return ErrExpired
+		}
+	}
+
+	expected := []byte(Sign(secret, timestamp, deliveryID, body))
+
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+
+	return ErrMismatch
+}
+
+// VerifyRequest checks the signature of a webhook request and returns its
+// body. The body of the request is replaced so it can be read again.
+func VerifyRequest(r *http.Request, secret string, tolerance time.Duration) (body []byte, err error) {
+	body, err = ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("Failed to read body: %w", err)
+	}
+
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	err = Verify(r.Header.Get(SignatureHeader), r.Header.Get(DeliveryHeader), body, secret, tolerance)
+	if err != nil {
+		return body, err
+	}
+
+	return body, nil
+}
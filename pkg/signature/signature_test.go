@@ -0,0 +1,190 @@
+package signature
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testBody       = []byte(`{"type":-1}`)
+	testDeliveryID = "123"
+	testTimestamp  = time.Unix(1617062400, 0)
+)
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 of "1617062400.123.{"type":-1}" with the key "secret".
+	want := "3a3388931c872ee3f7942c40fdff5d3c406be2c38f86cf9381022659cae522b6"
+
+	if got := Sign("secret", testTimestamp, testDeliveryID, testBody); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	header := Format(testTimestamp, testDeliveryID, testBody, "new", "", "old")
+
+	want := "t=1617062400" +
+		",v1=" + Sign("new", testTimestamp, testDeliveryID, testBody) +
+		",v1=" + Sign("old", testTimestamp, testDeliveryID, testBody)
+
+	if header != want {
+		t.Errorf("Format() = %s, want %s", header, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		header     string
+		signatures []string
+		err        error
+	}{
+		{"t=1617062400,v1=abc", []string{"abc"}, nil},
+		{"t=1617062400, v1=abc, v1=def", []string{"abc", "def"}, nil},
+		{"t=1617062400,v0=old,v1=abc", []string{"abc"}, nil},
+		{"v1=abc", nil, ErrInvalidHeader},
+		{"t=abc,v1=abc", nil, ErrInvalidHeader},
+		{"t=1617062400,v1", nil, ErrInvalidHeader},
+		{"", nil, ErrInvalidHeader},
+		{"t=1617062400", nil, ErrNoSignatures},
+	}
+
+	for _, test := range tests {
+		timestamp, signatures, err := Parse(test.header)
+		if !errors.Is(err, test.err) {
+			t.Errorf("Parse(%q) error = %v, want %v", test.header, err, test.err)
+
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !timestamp.Equal(testTimestamp) {
+			t.Errorf("Parse(%q) timestamp = %v, want %v", test.header, timestamp, testTimestamp)
+		}
+
+		if strings.Join(signatures, ",") != strings.Join(test.signatures, ",") {
+			t.Errorf("Parse(%q) signatures = %v, want %v", test.header, signatures, test.signatures)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		header     string
+		deliveryID string
+		body       []byte
+		secret     string
+		tolerance  time.Duration
+		err        error
+	}{
+		{
+			name:       "valid",
+			header:     Format(now, testDeliveryID, testBody, "secret"),
+			deliveryID: testDeliveryID, body: testBody, secret: "secret", tolerance: DefaultTolerance,
+		},
+		{
+			name:       "rotated secret signed with new",
+			header:     Format(now, testDeliveryID, testBody, "new", "old"),
+			deliveryID: testDeliveryID, body: testBody, secret: "new", tolerance: DefaultTolerance,
+		},
+		{
+			name:       "rotated secret signed with old",
+			header:     Format(now, testDeliveryID, testBody, "new", "old"),
+			deliveryID: testDeliveryID, body: testBody, secret: "old", tolerance: DefaultTolerance,
+		},
+		{
+			name:       "wrong secret",
+			header:     Format(now, testDeliveryID, testBody, "secret"),
+			deliveryID: testDeliveryID, body: testBody, secret: "other", tolerance: DefaultTolerance,
+			err: ErrMismatch,
+		},
+		{
+			name:       "tampered body",
+			header:     Format(now, testDeliveryID, testBody, "secret"),
+			deliveryID: testDeliveryID, body: []byte(`{"type":0}`), secret: "secret", tolerance: DefaultTolerance,
+			err: ErrMismatch,
+		},
+		{
+			name:       "different delivery",
+			header:     Format(now, testDeliveryID, testBody, "secret"),
+			deliveryID: "456", body: testBody, secret: "secret", tolerance: DefaultTolerance,
+			err: ErrMismatch,
+		},
+		{
+			name:       "replayed",
+			header:     Format(now.Add(-DefaultTolerance-time.Minute), testDeliveryID, testBody, "secret"),
+			deliveryID: testDeliveryID, body: testBody, secret: "secret", tolerance: DefaultTolerance,
+			err: ErrExpired,
+		},
+		{
+			name:       "from the future",
+			header:     Format(now.Add(DefaultTolerance+time.Minute), testDeliveryID, testBody, "secret"),
+			deliveryID: testDeliveryID, body: testBody, secret: "secret", tolerance: DefaultTolerance,
+			err: ErrExpired,
+		},
+		{
+			name:       "no tolerance",
+			header:     Format(testTimestamp, testDeliveryID, testBody, "secret"),
+			deliveryID: testDeliveryID, body: testBody, secret: "secret", tolerance: 0,
+		},
+		{
+			name:       "timestamp changed",
+			header:     strings.Replace(Format(now, testDeliveryID, testBody, "secret"), "t=", "t=1", 1),
+			deliveryID: testDeliveryID, body: testBody, secret: "secret", tolerance: 0,
+			err: ErrMismatch,
+		},
+		{
+			name:       "no signatures",
+			header:     Format(now, testDeliveryID, testBody),
+			deliveryID: testDeliveryID, body: testBody, secret: "secret", tolerance: DefaultTolerance,
+			err: ErrNoSignatures,
+		},
+	}
+
+	for _, test := range tests {
+		err := Verify(test.header, test.deliveryID, test.body, test.secret, test.tolerance)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: Verify() error = %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	r := httptest.NewRequest("POST", "/webhook", bytes.NewReader(testBody))
+	r.Header.Set(DeliveryHeader, testDeliveryID)
+	r.Header.Set(SignatureHeader, Format(time.Now(), testDeliveryID, testBody, "secret"))
+
+	body, err := VerifyRequest(r, "secret", DefaultTolerance)
+	if err != nil {
+		t.Fatalf("VerifyRequest() error = %v", err)
+	}
+
+	if !bytes.Equal(body, testBody) {
+		t.Errorf("VerifyRequest() body = %s, want %s", body, testBody)
+	}
+
+	// The body can still be read by the handler.
+	reread, err := ioutil.ReadAll(r.Body)
+	if err != nil || !bytes.Equal(reread, testBody) {
+		t.Errorf("request body = %s, %v, want %s", reread, err, testBody)
+	}
+
+	r = httptest.NewRequest("POST", "/webhook", bytes.NewReader(testBody))
+	r.Header.Set(DeliveryHeader, testDeliveryID)
+	r.Header.Set(SignatureHeader, Format(time.Now(), testDeliveryID, testBody, "secret"))
+
+	_, err = VerifyRequest(r, "other", DefaultTolerance)
+	if !errors.Is(err, ErrMismatch) {
+		t.Errorf("VerifyRequest() with wrong secret error = %v, want %v", err, ErrMismatch)
+	}
+}
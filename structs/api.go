@@ -1,11 +1,23 @@
 package structs
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
 	"time"
 
+	"github.com/TheRockettek/Errorly-Web/pkg/markdown"
+	"github.com/go-pg/pg/v10/orm"
 	"golang.org/x/xerrors"
 )
 
+func init() {
+	// Join tables have to be registered before they are used in a
+	// many2many relation.
+	orm.RegisterTable((*IssueLabel)(nil))
+}
+
 // UserType signifies how they have authenticated into Errorly.
 type UserType uint8
 
@@ -43,8 +55,76 @@ const (
 	// IssueMarkStatus signifies the status of an issue has changed.
 	// The project, issue and invoauthorkee are attached.
 	IssueMarkStatus
+	// IssueLabeled signifies labels were added to or removed from an
+	// issue. The project, issue, author and changed labels are attached.
+	IssueLabeled
+	// IssueCommentEdited signifies a comment was edited. The project,
+	// issue, author and updated comment are attached.
+	IssueCommentEdited
+	// IssueCommentDeleted signifies a comment was deleted. The project,
+	// issue, author and deleted comment are attached.
+	IssueCommentDeleted
 )
 
+func (wet WebhookEventType) String() string {
+	switch wet {
+	case TestWebhook:
+		return "test"
+	case IssueCreate:
+		return "issue_create"
+	case IssueComment:
+		return "issue_comment"
+	case IssueStarred:
+		return "issue_starred"
+	case IssueAssigned:
+		return "issue_assigned"
+	case IssueLocked:
+		return "issue_locked"
+	case IssueMarkStatus:
+		return "issue_mark_status"
+	case IssueLabeled:
+		return "issue_labeled"
+	case IssueCommentEdited:
+		return "issue_comment_edited"
+	case IssueCommentDeleted:
+		return "issue_comment_deleted"
+	}
+
+	return ""
+}
+
+// ParseWebhookEventType converts a response string into a WebhookEventType value.
+// Returns an error if the input string does not match known values.
+func ParseWebhookEventType(webhookEventTypeStr string) (WebhookEventType, error) {
+	for eventType := TestWebhook; eventType.String() != ""; eventType++ {
+		if eventType.String() == webhookEventTypeStr {
+			return eventType, nil
+		}
+	}
+
+	return TestWebhook, xerrors.Errorf("Unknown WebhookEventType String: '%s'", webhookEventTypeStr)
+}
+
+// WebhookEventMask is a set of WebhookEventTypes a webhook receives. An
+// empty mask receives every event.
+type WebhookEventMask uint32
+
+// Has returns if the mask includes an event type.
+func (wem WebhookEventMask) Has(eventType WebhookEventType) bool {
+	return wem == 0 || wem&eventBit(eventType) != 0
+}
+
+// Add returns the mask including an event type.
+func (wem WebhookEventMask) Add(eventType WebhookEventType) WebhookEventMask {
+	return wem | eventBit(eventType)
+}
+
+// eventBit returns the bit of an event type in a WebhookEventMask. Event
+// types start at -1 so they are offset by one.
+func eventBit(eventType WebhookEventType) WebhookEventMask {
+	return 1 << uint(eventType+1)
+}
+
 // WebhookType signifies how the payload should be sent.
 type WebhookType uint8
 
@@ -55,8 +135,56 @@ const (
 	// DiscordWebhook denotes the payload should supply a payload
 	// that would accept by a discord webhook.
 	DiscordWebhook
+	// SlackWebhook denotes the payload should be Block Kit message
+	// accepted by a slack incoming webhook.
+	SlackWebhook
+	// TeamsWebhook denotes the payload should be an Adaptive Card
+	// accepted by a microsoft teams incoming webhook.
+	TeamsWebhook
+	// MatrixWebhook denotes the payload should be the content of a
+	// matrix m.room.message event.
+	MatrixWebhook
+	// TemplateWebhook denotes the payload should be rendered from the
+	// template of the webhook.
+	TemplateWebhook
+	// PagerDutyWebhook denotes the payload should be a PagerDuty Events
+	// API v2 event.
+	PagerDutyWebhook
 )
 
+func (wt WebhookType) String() string {
+	switch wt {
+	case RegularPayload:
+		return "regular"
+	case DiscordWebhook:
+		return "discord"
+	case SlackWebhook:
+		return "slack"
+	case TeamsWebhook:
+		return "teams"
+	case MatrixWebhook:
+		return "matrix"
+	case TemplateWebhook:
+		return "template"
+	case PagerDutyWebhook:
+		return "pagerduty"
+	}
+
+	return ""
+}
+
+// ParseWebhookType converts a response string into a WebhookType value.
+// Returns an error if the input string does not match known values.
+func ParseWebhookType(webhookTypeStr string) (WebhookType, error) {
+	for webhookType := RegularPayload; webhookType.String() != ""; webhookType++ {
+		if webhookType.String() == webhookTypeStr {
+			return webhookType, nil
+		}
+	}
+
+	return RegularPayload, xerrors.Errorf("Unknown WebhookType String: '%s'", webhookTypeStr)
+}
+
 // ContentType signifies the comment type.
 type ContentType uint8
 
@@ -69,6 +197,12 @@ const (
 	// CommentsLocked denotes comments have been locked or unlocked.
 	// Marked by a boolean as data.
 	CommentsLocked
+	// AssigneeChanged denotes the issue has been assigned or unassigned.
+	// The new assignee ID is available in data, 0 if unassigned.
+	AssigneeChanged
+	// StarChanged denotes the issue has been starred or unstarred.
+	// Marked by a boolean as data.
+	StarChanged
 )
 
 // EntryType signifies the entry status type.
@@ -83,6 +217,9 @@ const (
 	EntryInvalid
 	// EntryResolved means an issue has been fixed.
 	EntryResolved
+	// EntrySnoozed means an issue has been silenced until a condition
+	// is met, after which it will become active again.
+	EntrySnoozed
 )
 
 func (eT EntryType) String() string {
@@ -95,6 +232,8 @@ func (eT EntryType) String() string {
 		return "invalid"
 	case EntryResolved:
 		return "resolved"
+	case EntrySnoozed:
+		return "snoozed"
 	}
 
 	return ""
@@ -112,11 +251,75 @@ func ParseEntryType(entryTypeStr string) (EntryType, error) {
 		return EntryInvalid, nil
 	case EntryResolved.String():
 		return EntryResolved, nil
+	case EntrySnoozed.String():
+		return EntrySnoozed, nil
 	}
 
 	return EntryActive, xerrors.Errorf("Unknown EntryType String: '%s', defaulting to EntryActive", entryTypeStr)
 }
 
+// Priority signifies the impact of an issue.
+type Priority uint8
+
+const (
+	// PriorityLow is the default priority of an issue.
+	PriorityLow Priority = iota
+	// PriorityMedium means an issue should be looked at.
+	PriorityMedium
+	// PriorityHigh means an issue should be fixed soon.
+	PriorityHigh
+	// PriorityCritical means an issue needs to be fixed immediately.
+	PriorityCritical
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityMedium:
+		return "medium"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	}
+
+	return ""
+}
+
+// Color returns the color used to display the priority.
+func (p Priority) Color() int {
+	switch p {
+	case PriorityLow:
+		return 0x95a5a6
+	case PriorityMedium:
+		return 0xf1c40f
+	case PriorityHigh:
+		return 0xe67e22
+	case PriorityCritical:
+		return 0xe74c3c
+	}
+
+	return 0
+}
+
+// ParsePriority converts a response string into a Priority value.
+// Returns an error if the input string does not match known values.
+func ParsePriority(priorityStr string) (Priority, error) {
+	switch priorityStr {
+	case PriorityLow.String():
+		return PriorityLow, nil
+	case PriorityMedium.String():
+		return PriorityMedium, nil
+	case PriorityHigh.String():
+		return PriorityHigh, nil
+	case PriorityCritical.String():
+		return PriorityCritical, nil
+	}
+
+	return PriorityLow, xerrors.Errorf("Unknown Priority String: '%s', defaulting to PriorityLow", priorityStr)
+}
+
 // User contains the structure of a user.
 type User struct {
 	ID   int64  `json:"id"`
@@ -138,6 +341,8 @@ type User struct {
 	Integration bool  `json:"integration" pg:",use_zero"`
 
 	Token string `json:"-"`
+
+	NotificationWebhook string `json:"-"` // Discord webhook mentions are delivered to, empty disables
 }
 
 // Project contains the structure of a project.
@@ -178,6 +383,19 @@ type ProjectSettings struct {
 
 	Limited        bool    `json:"limited" pg:",use_zero"`        // When enabled, only contributes can create errors
 	ContributorIDs []int64 `json:"contributor_ids" pg:",notnull"` // Contributors for project
+
+	DefaultSearchID int64 `json:"default_search_id" pg:",use_zero"` // Shared saved search used when no query is passed
+
+	AutoResolveDays int `json:"auto_resolve_days" pg:",use_zero"` // Resolve issues with no occurrences in this many days, 0 disables
+
+	PriorityRules []PriorityRule `json:"priority_rules"` // Rules used to set the priority of new issues
+}
+
+// PriorityRule sets the priority of new issues whose error matches the
+// pattern. The first matching rule of a project is used.
+type PriorityRule struct {
+	Pattern  string   `json:"pattern"` // Regular expression matched against the issue error
+	Priority Priority `json:"priority"`
 }
 
 // Webhook contains the structure of a webhook integration.
@@ -195,7 +413,137 @@ type Webhook struct {
 	JSONContent bool        `json:"json_content" pg:",use_zero"` // When true, uses json else urlencoded
 	Active      bool        `json:"active" pg:",use_zero"`       // Boolean if it is enabled
 
+	// When the secret is changed, the previous secret is still used to sign
+	// deliveries until it expires so receivers can be updated.
+	PreviousSecret          string    `json:"previous_secret,omitempty"`
+	PreviousSecretExpiresAt time.Time `json:"previous_secret_expires_at,omitempty"`
+
 	Failures uint8 `json:"failures"` // If 4 failures sending webhook, will disable webhook
+
+	CloudEvents CloudEventsMode `json:"cloudevents" pg:",use_zero"` // Only used by RegularPayload webhooks
+
+	// Go text/template rendered against the WebhookMessage when the type
+	// is TemplateWebhook.
+	Template            string `json:"template,omitempty"`
+	TemplateContentType string `json:"template_content_type,omitempty"`
+
+	RoutingKey string `json:"routing_key,omitempty"` // Integration key of the service when the type is PagerDutyWebhook
+
+	Events  WebhookEventMask `json:"events" pg:",use_zero"` // Events the webhook receives
+	Filters WebhookFilters   `json:"filters"`               // Issues the webhook receives events for
+}
+
+// WebhookFilters limits the issues a webhook receives events for. Empty
+// filters match every issue.
+type WebhookFilters struct {
+	Environments []string `json:"environments,omitempty"`
+	MinPriority  Priority `json:"min_priority"`
+	Labels       []string `json:"labels,omitempty"` // Issue must have at least one of the labels
+	Query        string   `json:"query,omitempty"`  // Issue query the issue must match
+}
+
+// CloudEventsMode signifies if and how RegularPayload webhooks are sent as
+// CloudEvents.
+type CloudEventsMode uint8
+
+const (
+	// CloudEventsDisabled sends the payload without a CloudEvents envelope.
+	CloudEventsDisabled CloudEventsMode = iota
+	// CloudEventsStructured sends the event attributes and payload together
+	// as an application/cloudevents+json body.
+	CloudEventsStructured
+	// CloudEventsBinary sends the event attributes as ce- headers and the
+	// payload as the body.
+	CloudEventsBinary
+)
+
+func (cem CloudEventsMode) String() string {
+	switch cem {
+	case CloudEventsDisabled:
+		return "disabled"
+	case CloudEventsStructured:
+		return "structured"
+	case CloudEventsBinary:
+		return "binary"
+	}
+
+	return ""
+}
+
+// ParseCloudEventsMode converts a response string into a CloudEventsMode value.
+// Returns an error if the input string does not match known values.
+func ParseCloudEventsMode(cloudEventsModeStr string) (CloudEventsMode, error) {
+	for mode := CloudEventsDisabled; mode.String() != ""; mode++ {
+		if mode.String() == cloudEventsModeStr {
+			return mode, nil
+		}
+	}
+
+	return CloudEventsDisabled, xerrors.Errorf("Unknown CloudEventsMode String: '%s'", cloudEventsModeStr)
+}
+
+// DeliveryStatus signifies the state of a webhook delivery.
+type DeliveryStatus uint8
+
+const (
+	// DeliveryPending means the delivery is waiting to be sent or retried.
+	DeliveryPending DeliveryStatus = iota
+	// DeliverySucceeded means the webhook accepted the delivery.
+	DeliverySucceeded
+	// DeliveryFailed means the delivery will not be retried.
+	DeliveryFailed
+)
+
+func (ds DeliveryStatus) String() string {
+	switch ds {
+	case DeliveryPending:
+		return "pending"
+	case DeliverySucceeded:
+		return "succeeded"
+	case DeliveryFailed:
+		return "failed"
+	}
+
+	return ""
+}
+
+// WebhookDelivery is the structure of a queued webhook payload.
+type WebhookDelivery struct {
+	ID        int64 `json:"id"`
+	WebhookID int64 `json:"webhook_id"`
+	ProjectID int64 `json:"project_id"`
+
+	CreatedAt time.Time      `json:"created_at" pg:"default:now()"`
+	Payload   WebhookMessage `json:"payload"`
+
+	Status        DeliveryStatus `json:"status" pg:",use_zero"`
+	Attempts      int            `json:"attempts" pg:",use_zero"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     string         `json:"last_error,omitempty"`
+	CompletedAt   time.Time      `json:"completed_at"`
+
+	AttemptLog []*WebhookDeliveryAttempt `json:"attempt_log,omitempty" pg:"rel:has-many,join_fk:delivery_id"`
+}
+
+// WebhookDeliveryAttempt is the structure of a single request made when
+// sending a webhook delivery.
+type WebhookDeliveryAttempt struct {
+	ID         int64 `json:"id"`
+	DeliveryID int64 `json:"delivery_id"`
+	WebhookID  int64 `json:"webhook_id"`
+	ProjectID  int64 `json:"project_id"`
+
+	CreatedAt time.Time `json:"created_at" pg:"default:now()"`
+
+	RequestURL     string            `json:"request_url"`
+	RequestHeaders map[string]string `json:"request_headers"`
+	RequestBody    string            `json:"request_body"`
+
+	StatusCode   int    `json:"status_code" pg:",use_zero"`
+	ResponseBody string `json:"response_body"` // Truncated to the first 4KB
+
+	Latency int64  `json:"latency" pg:",use_zero"` // Milliseconds
+	Error   string `json:"error,omitempty"`
 }
 
 // IssueEntry contains the structure of an issue entry.
@@ -203,20 +551,42 @@ type IssueEntry struct {
 	ID        int64 `json:"id"`
 	ProjectID int64 `json:"project_id"`
 
-	Starred bool `json:"starred" pg:",use_zero"`
+	Starred bool     `json:"starred" pg:",use_zero"`
+	Labels  []*Label `json:"labels,omitempty" pg:"many2many:issue_labels,fk:issue_id,join_fk:label_id"`
 
 	Type        EntryType `json:"type"`
+	Priority    Priority  `json:"priority" pg:",use_zero"`
 	Occurrences int       `json:"occurrences"`
 	Assignee    *User     `json:"assignee,omitempty" pg:"rel:has-one"`
 	AssigneeID  int64     `json:"assignee_id" pg:",use_zero"`
 
-	Error       string `json:"error"`
-	Function    string `json:"function"`
-	Checkpoint  string `json:"checkpoint"`
-	Description string `json:"description"`
-	Traceback   string `json:"traceback"`
+	Error           string `json:"error"`
+	Function        string `json:"function"`
+	Checkpoint      string `json:"checkpoint"`
+	Description     string `json:"description"` // Markdown
+	DescriptionHTML string `json:"description_html" pg:"-"`
+	Traceback       string `json:"traceback"`
+
+	Environment string `json:"environment"` // Environment the issue was last seen in
+	Release     string `json:"release"`     // Release the issue was last seen in
+
+	UsersAffected int `json:"users_affected" pg:",use_zero"` // Distinct users that have reported the issue
+
+	Reactions     map[string]int `json:"reactions"`                     // Count of each reaction
+	ReactionCount int            `json:"reaction_count" pg:",use_zero"` // Total reactions, used for sorting
+
+	// When set, the issue was resolved in this release. Occurrences from
+	// older releases will not reopen the issue.
+	ResolvedInRelease     string `json:"resolved_in_release,omitempty"`
+	ResolvedInNextRelease bool   `json:"resolved_in_next_release,omitempty"` // Resolved in the release after ResolvedInRelease
+
+	// Snoozed issues become active once any of the conditions are met.
+	SnoozedUntil      time.Time `json:"snoozed_until,omitempty"`
+	SnoozeOccurrences int       `json:"snooze_occurrences,omitempty"` // Occurrences the issue will wake at
+	SnoozeUsers       int       `json:"snooze_users,omitempty"`       // Users affected the issue will wake at
 
 	LastModified time.Time `json:"last_modified" pg:"default:now()"`
+	LastSeen     time.Time `json:"last_seen" pg:"default:now()"` // Time of the latest occurrence
 
 	CreatedAt   time.Time `json:"created_at" pg:"default:now()"`
 	CreatedBy   *User     `json:"created_by,omitempty" pg:"rel:has-one"`
@@ -227,6 +597,32 @@ type IssueEntry struct {
 	Comments       []*Comment `json:"comment_ids,omitempty" pg:"rel:has-many,join_fk:issue_id"`
 }
 
+// Fingerprint returns a stable identifier of the issue. Occurrences are
+// grouped into an issue by their error and function, so the fingerprint
+// stays the same for the lifetime of the issue.
+func (ie *IssueEntry) Fingerprint() string {
+	hash := sha256.New()
+	hash.Write([]byte(strconv.FormatInt(ie.ProjectID, 10)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ie.Error))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ie.Function))
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// RenderMarkdown renders the description of the issue as HTML.
+func (ie *IssueEntry) RenderMarkdown() {
+	ie.DescriptionHTML = markdown.Render(ie.Description)
+}
+
+// AfterScan renders the markdown of the issue when it is selected.
+func (ie *IssueEntry) AfterScan(ctx context.Context) error {
+	ie.RenderMarkdown()
+
+	return nil
+}
+
 // Comment contains the structure of an issue comment.
 type Comment struct {
 	ID      int64 `json:"id"`
@@ -237,9 +633,266 @@ type Comment struct {
 	CreatedByID int64     `json:"created_by_id" pg:",use_zero"`
 
 	Type           ContentType `json:"type"`
-	Content        *string     `json:"content,omitempty"`
+	Content        *string     `json:"content,omitempty"` // Markdown
+	ContentHTML    string      `json:"content_html,omitempty" pg:"-"`
 	IssueMarked    *EntryType  `json:"issue_marked,omitempty" pg:",use_zero"`
 	CommentsOpened *bool       `json:"comments_opened,omitempty" pg:",use_zero"`
+	AssigneeID     *int64      `json:"assignee_id,omitempty" pg:",use_zero"`
+	Starred        *bool       `json:"starred,omitempty" pg:",use_zero"`
+
+	EditedAt *time.Time     `json:"edited_at,omitempty"` // Nil if the comment was never edited
+	Edits    []*CommentEdit `json:"edits,omitempty" pg:"rel:has-many"`
+
+	Reactions map[string]int `json:"reactions,omitempty"` // Count of each reaction
+}
+
+// RenderMarkdown renders the content of the comment as HTML.
+func (c *Comment) RenderMarkdown() {
+	if c.Content != nil {
+		c.ContentHTML = markdown.Render(*c.Content)
+	}
+}
+
+// AfterScan renders the markdown of the comment when it is selected.
+func (c *Comment) AfterScan(ctx context.Context) error {
+	c.RenderMarkdown()
+
+	return nil
+}
+
+// CommentEdit stores the content of a comment before it was edited.
+type CommentEdit struct {
+	ID        int64 `json:"id"`
+	CommentID int64 `json:"comment_id"`
+
+	EditedAt   time.Time `json:"edited_at" pg:"default:now()"`
+	EditedBy   *User     `json:"edited_by,omitempty" pg:"rel:has-one"`
+	EditedByID int64     `json:"edited_by_id" pg:",use_zero"`
+
+	Content string `json:"content"`
+}
+
+// NotificationReason signifies why a user received a notification.
+type NotificationReason uint8
+
+const (
+	// NotificationMentioned signifies the user was mentioned in a comment.
+	NotificationMentioned NotificationReason = iota
+	// NotificationSubscribed signifies the user is subscribed to the issue.
+	NotificationSubscribed
+)
+
+func (nr NotificationReason) String() string {
+	switch nr {
+	case NotificationMentioned:
+		return "mentioned"
+	case NotificationSubscribed:
+		return "subscribed"
+	}
+
+	return ""
+}
+
+// Notification is the structure of a notification in a users inbox.
+type Notification struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+
+	ProjectID int64       `json:"project_id"`
+	IssueID   int64       `json:"issue_id"`
+	Issue     *IssueEntry `json:"issue,omitempty" pg:"rel:has-one"`
+	CommentID int64       `json:"comment_id,omitempty" pg:",use_zero"`
+
+	Type   WebhookEventType   `json:"type" pg:",use_zero"`
+	Reason NotificationReason `json:"reason" pg:",use_zero"`
+
+	CreatedAt time.Time `json:"created_at" pg:"default:now()"`
+	AuthorID  int64     `json:"author_id" pg:",use_zero"`
+	Author    *User     `json:"author,omitempty" pg:"rel:has-one"`
+
+	Read bool `json:"read" pg:",use_zero"`
+}
+
+// AuditAction signifies the change recorded in a project audit log.
+type AuditAction uint8
+
+const (
+	// AuditProjectUpdate signifies the project settings were updated.
+	AuditProjectUpdate AuditAction = iota
+	// AuditProjectTransfer signifies the project was transferred to
+	// another user.
+	AuditProjectTransfer
+	// AuditContributorRemove signifies a contributor was removed.
+	AuditContributorRemove
+	// AuditIntegrationCreate signifies an integration was created.
+	AuditIntegrationCreate
+	// AuditIntegrationRegenerate signifies the token of an integration
+	// was regenerated.
+	AuditIntegrationRegenerate
+	// AuditIntegrationDelete signifies an integration was deleted.
+	AuditIntegrationDelete
+	// AuditWebhookCreate signifies a webhook was created.
+	AuditWebhookCreate
+	// AuditWebhookDelete signifies a webhook was deleted.
+	AuditWebhookDelete
+	// AuditInviteUse signifies a user joined the project with an invite.
+	AuditInviteUse
+	// AuditWebhookUpdate signifies a webhook was updated.
+	AuditWebhookUpdate
+)
+
+func (aa AuditAction) String() string {
+	switch aa {
+	case AuditProjectUpdate:
+		return "project_update"
+	case AuditProjectTransfer:
+		return "project_transfer"
+	case AuditContributorRemove:
+		return "contributor_remove"
+	case AuditIntegrationCreate:
+		return "integration_create"
+	case AuditIntegrationRegenerate:
+		return "integration_regenerate"
+	case AuditIntegrationDelete:
+		return "integration_delete"
+	case AuditWebhookCreate:
+		return "webhook_create"
+	case AuditWebhookDelete:
+		return "webhook_delete"
+	case AuditInviteUse:
+		return "invite_use"
+	case AuditWebhookUpdate:
+		return "webhook_update"
+	}
+
+	return ""
+}
+
+// ParseAuditAction converts a response string into an AuditAction value.
+// Returns an error if the input string does not match known values.
+func ParseAuditAction(auditActionStr string) (AuditAction, error) {
+	for action := AuditProjectUpdate; action.String() != ""; action++ {
+		if action.String() == auditActionStr {
+			return action, nil
+		}
+	}
+
+	return AuditProjectUpdate, xerrors.Errorf("Unknown AuditAction String: '%s'", auditActionStr)
+}
+
+// AuditChange is the value of a field before and after it was changed.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditLogEntry is the structure of a change made to a project.
+type AuditLogEntry struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+
+	CreatedAt time.Time `json:"created_at" pg:"default:now()"`
+	ActorID   int64     `json:"actor_id" pg:",use_zero"`
+	Actor     *User     `json:"actor,omitempty" pg:"rel:has-one"`
+
+	Action AuditAction `json:"action" pg:",use_zero"`
+	Target string      `json:"target"` // ID of the user, integration, webhook or invite code changed
+
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+
+	Diff map[string]AuditChange `json:"diff"`
+}
+
+// Activity is the structure of an action performed on an issue.
+type Activity struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+	IssueID   int64 `json:"issue_id"`
+
+	CreatedAt time.Time `json:"created_at" pg:"default:now()"`
+	ActorID   int64     `json:"actor_id" pg:",use_zero"`
+	Actor     *User     `json:"actor,omitempty" pg:"rel:has-one"`
+
+	Action ActionType  `json:"action" pg:",use_zero"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Reactions are the emoji that can be reacted with.
+var Reactions = []string{"+1", "-1", "laugh", "hooray", "confused", "heart", "rocket", "eyes"}
+
+// IsValidReaction returns if an emoji can be reacted with.
+func IsValidReaction(emoji string) bool {
+	for _, reaction := range Reactions {
+		if reaction == emoji {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reaction is the structure of a users reaction to an issue or comment.
+// CommentID is 0 when reacting to the issue itself.
+type Reaction struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+
+	IssueID   int64  `json:"issue_id" pg:",unique:reaction"`
+	CommentID int64  `json:"comment_id" pg:",use_zero,unique:reaction"`
+	UserID    int64  `json:"user_id" pg:",unique:reaction"`
+	Emoji     string `json:"emoji" pg:",unique:reaction"`
+
+	CreatedAt time.Time `json:"created_at" pg:"default:now()"`
+}
+
+// IssueSubscription is the structure of a user watching an issue.
+type IssueSubscription struct {
+	IssueID int64 `json:"issue_id" pg:",pk,type:bigint"`
+	UserID  int64 `json:"user_id" pg:",pk,type:bigint"`
+
+	CreatedAt time.Time `json:"created_at" pg:"default:now()"`
+}
+
+// Label is the structure of a project label which can be added to issues.
+type Label struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+
+	CreatedAt   time.Time `json:"created_at" pg:"default:now()"`
+	CreatedBy   *User     `json:"created_by,omitempty" pg:"rel:has-one"`
+	CreatedByID int64     `json:"created_by_id" pg:",use_zero"`
+
+	Name        string `json:"name"`
+	Color       string `json:"color"` // Hex color such as #ff0000
+	Description string `json:"description"`
+}
+
+// IssueLabel is the join table between issues and labels.
+type IssueLabel struct {
+	IssueID int64 `json:"issue_id" pg:",pk,type:bigint"`
+	LabelID int64 `json:"label_id" pg:",pk,type:bigint"`
+}
+
+// Release is the structure of a release of a project. Releases are ordered
+// by when they were first seen.
+type Release struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id" pg:",unique:project_version"`
+
+	Version   string    `json:"version" pg:",unique:project_version"`
+	FirstSeen time.Time `json:"first_seen" pg:"default:now()"`
+}
+
+// AffectedUser is the structure of a user who has encountered an issue.
+// Identifier is provided by the integration and is not an Errorly user.
+type AffectedUser struct {
+	ID      int64 `json:"id"`
+	IssueID int64 `json:"issue_id" pg:",unique:issue_identifier"`
+
+	Identifier string    `json:"identifier" pg:",unique:issue_identifier"`
+	FirstSeen  time.Time `json:"first_seen" pg:"default:now()"`
 }
 
 // InviteCode is the structure of an invite.
@@ -260,6 +913,22 @@ type InviteCode struct {
 	ExpiresBy time.Time `json:"expires_by" pg:",use_zero"`
 }
 
+// SavedSearch is the structure of a saved issue query. Saved searches
+// are only visible to their creator unless they have been shared with
+// the project.
+type SavedSearch struct {
+	ID        int64 `json:"id"`
+	ProjectID int64 `json:"project_id"`
+
+	CreatedAt   time.Time `json:"created_at" pg:"default:now()"`
+	CreatedBy   *User     `json:"created_by,omitempty" pg:"rel:has-one"`
+	CreatedByID int64     `json:"created_by_id" pg:",use_zero"`
+
+	Name   string `json:"name"`
+	Query  string `json:"query"`
+	Shared bool   `json:"shared" pg:",use_zero"`
+}
+
 // WebhookMessage is the generic payload for all webhook messages.
 type WebhookMessage struct {
 	Type WebhookEventType `json:"type"`
@@ -268,6 +937,9 @@ type WebhookMessage struct {
 	Issue   *IssueEntry `json:"issue,omitempty"`
 	Comment *Comment    `json:"comment,omitempty"`
 
+	AddedLabels   []*Label `json:"added_labels,omitempty"`
+	RemovedLabels []*Label `json:"removed_labels,omitempty"`
+
 	Author *User `json:"author,omitempty"`
 }
 
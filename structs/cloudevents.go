@@ -0,0 +1,14 @@
+package structs
+
+// CloudEvent is the structure of a CloudEvents 1.0 event in structured
+// content mode.
+type CloudEvent struct {
+	SpecVersion     string      `json:"specversion"`
+	Type            string      `json:"type"`
+	Source          string      `json:"source"`
+	ID              string      `json:"id"`
+	Time            string      `json:"time"`
+	Subject         string      `json:"subject,omitempty"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+}
@@ -0,0 +1,9 @@
+package structs
+
+// MatrixMessage is the content of a matrix m.room.message event.
+type MatrixMessage struct {
+	MsgType       string `json:"msgtype"` // m.text or m.notice
+	Body          string `json:"body"`    // Plain text fallback
+	Format        string `json:"format,omitempty"`
+	FormattedBody string `json:"formatted_body,omitempty"`
+}
@@ -0,0 +1,42 @@
+package structs
+
+// PagerDutyEvent is the structure of a PagerDuty Events API v2 event.
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger, acknowledge or resolve
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"` // Only required when triggering
+	Client      string            `json:"client,omitempty"`
+	ClientURL   string            `json:"client_url,omitempty"`
+	Links       []*PagerDutyLink  `json:"links,omitempty"`
+}
+
+// PagerDutyPayload describes the alert of a trigger event.
+type PagerDutyPayload struct {
+	Summary       string                  `json:"summary"`
+	Source        string                  `json:"source"`
+	Severity      string                  `json:"severity"` // critical, error, warning or info
+	Timestamp     string                  `json:"timestamp,omitempty"`
+	Component     string                  `json:"component,omitempty"`
+	Group         string                  `json:"group,omitempty"`
+	Class         string                  `json:"class,omitempty"`
+	CustomDetails *PagerDutyCustomDetails `json:"custom_details,omitempty"`
+}
+
+// PagerDutyCustomDetails contains the details of an issue shown on the
+// alert of a trigger event.
+type PagerDutyCustomDetails struct {
+	Status        string `json:"status"`
+	Priority      string `json:"priority"`
+	Occurrences   int    `json:"occurrences"`
+	UsersAffected int    `json:"users_affected"`
+	Checkpoint    string `json:"checkpoint,omitempty"`
+	Release       string `json:"release,omitempty"`
+	Traceback     string `json:"traceback,omitempty"`
+}
+
+// PagerDutyLink is a link shown on the incident of an event.
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text,omitempty"`
+}
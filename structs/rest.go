@@ -1,6 +1,10 @@
 package structs
 
-import "golang.org/x/xerrors"
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+)
 
 // ActionType signifies the action type of a task.
 type ActionType uint8
@@ -15,6 +19,14 @@ const (
 	ActionLockComments
 	// ActionMarkStatus signifies the status of an issue is changing.
 	ActionMarkStatus
+	// ActionSnooze signifies an issue is being silenced until a time,
+	// number of occurrences or number of users affected.
+	ActionSnooze
+	// ActionLabel signifies labels are being added to or removed from
+	// an issue.
+	ActionLabel
+	// ActionPriority signifies the priority of an issue is changing.
+	ActionPriority
 )
 
 // ParseActionType converts a response string into a ActionType value.
@@ -29,11 +41,47 @@ func ParseActionType(actionTypeStr string) (ActionType, error) {
 		return ActionLockComments, nil
 	case "mark_status":
 		return ActionMarkStatus, nil
+	case "snooze":
+		return ActionSnooze, nil
+	case "label":
+		return ActionLabel, nil
+	case "priority":
+		return ActionPriority, nil
 	}
 
 	return ActionStar, xerrors.Errorf("Unknown EntryType String: '%s', defaulting to EntryActive", actionTypeStr)
 }
 
+// ReleaseResolution signifies which release an issue has been resolved in.
+type ReleaseResolution uint8
+
+const (
+	// ResolvedNow signifies the issue is resolved and any new occurrence
+	// is a regression.
+	ResolvedNow ReleaseResolution = iota
+	// ResolvedInCurrentRelease signifies the issue is fixed in the release
+	// it was last seen in.
+	ResolvedInCurrentRelease
+	// ResolvedInNextRelease signifies the issue will be fixed in the release
+	// after the one it was last seen in.
+	ResolvedInNextRelease
+)
+
+// ParseReleaseResolution converts a response string into a ReleaseResolution value.
+// Returns an error if the input string does not match known values.
+func ParseReleaseResolution(releaseResolutionStr string) (ReleaseResolution, error) {
+	switch releaseResolutionStr {
+	case "":
+		return ResolvedNow, nil
+	case "current":
+		return ResolvedInCurrentRelease, nil
+	case "next":
+		return ResolvedInNextRelease, nil
+	}
+
+	return ResolvedNow, xerrors.Errorf("Unknown ReleaseResolution String: '%s', defaulting to ResolvedNow", releaseResolutionStr)
+}
+
 // BaseResponse is the structure of all REST requests.
 type BaseResponse struct {
 	Success bool        `json:"success"`
@@ -48,6 +96,74 @@ type APIMe struct {
 	Projects      []PartialProject `json:"projects"`
 }
 
+// APIProjectAudit is the structure of the /api/project/{id}/audit endpoint.
+type APIProjectAudit struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	Entries []AuditLogEntry `json:"entries"`
+}
+
+// APIProjectWebhookDeliveries is the structure of the
+// /api/project/{project_id}/webhook/{webhook_id}/deliveries endpoint.
+type APIProjectWebhookDeliveries struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// APIProjectWebhookPreview is the structure of the
+// /api/project/{project_id}/webhook/preview endpoint.
+type APIProjectWebhookPreview struct {
+	ContentType string `json:"content_type"`
+	Body        string `json:"body"`
+}
+
+// APIProjectIssueTimeline is the structure of the
+// /api/project/{project_id}/issue/{issue_id}/timeline endpoint.
+type APIProjectIssueTimeline struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	Timeline []TimelineEntry `json:"timeline"`
+	End      bool            `json:"end"`
+}
+
+// TimelineEntry is either a comment or an activity on an issue.
+type TimelineEntry struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Comment  *Comment  `json:"comment,omitempty"`
+	Activity *Activity `json:"activity,omitempty"`
+}
+
+// APIProjectReactions is the structure of the
+// /api/project/{project_id}/issue/{issue_id}/reactions endpoint.
+type APIProjectReactions struct {
+	Reactions map[string][]int64    `json:"reactions"` // Emoji to the IDs of users who reacted
+	Users     map[int64]PartialUser `json:"users"`
+}
+
+// APIProjectIssueSubscribers is the structure of the
+// /api/project/{project_id}/issue/{issue_id}/subscribers endpoint.
+type APIProjectIssueSubscribers struct {
+	Subscribed    bool    `json:"subscribed"`
+	SubscriberIDs []int64 `json:"subscriber_ids"`
+}
+
+// APIMeNotifications is the structure of the /api/me/notifications endpoint.
+type APIMeNotifications struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	Unread  int    `json:"unread"`
+	Webhook string `json:"webhook"`
+
+	Notifications []Notification `json:"notifications"`
+}
+
 // PartialProject is a partial version of a project object.
 type PartialProject struct {
 	ID          int64  `json:"id"`
@@ -93,22 +209,51 @@ type APIProjectExecutor struct {
 // APIProjectIssues is the structure of the GET /api/project/{id}/issues endpoint.
 type APIProjectIssues struct {
 	Page        int          `json:"page"`
+	Limit       int          `json:"limit"`
+	NextCursor  string       `json:"next_cursor,omitempty"`
+	Query       string       `json:"query"`
 	TotalIssues int          `json:"total_issues"`
 	Issues      []IssueEntry `json:"issues,omitempty"`
 	Issue       *IssueEntry  `json:"issue,omitempty"`
+
+	Facets *APIProjectIssueFacets `json:"facets,omitempty"`
+}
+
+// APIProjectIssueFacets contains the number of issues matching a query grouped by
+// their values.
+type APIProjectIssueFacets struct {
+	Status      map[string]int `json:"status"`
+	Assignee    map[int64]int  `json:"assignee"` // Unassigned issues are under 0
+	Starred     int            `json:"starred"`
+	Environment map[string]int `json:"environment"`
+	Release     map[string]int `json:"release"`
+}
+
+// APIProjectSearches is the structure of the GET /api/project/{id}/searches endpoint.
+type APIProjectSearches struct {
+	Searches        []SavedSearch `json:"searches"`
+	DefaultSearchID int64         `json:"default_search_id"`
+}
+
+// APIProjectLabels is the structure of the GET /api/project/{id}/labels endpoint.
+type APIProjectLabels struct {
+	Labels []Label `json:"labels"`
 }
 
 // APIProjectIssueCreate is the structure of the POST /api/project/{id}/issues endpoint.
 type APIProjectIssueCreate struct {
-	New   bool        `json:"new"`
-	Issue *IssueEntry `json:"issue"`
+	New       bool        `json:"new"`
+	Regressed bool        `json:"regressed"` // True if the issue was resolved and has been reopened
+	Issue     *IssueEntry `json:"issue"`
 }
 
 // APIProjectIssueComments is the structure of the GET /api/project/{id}/issues/{issue_id}/comments endpoint.
 type APIProjectIssueComments struct {
-	Page     int       `json:"page"`
-	Comments []Comment `json:"comments"`
-	End      bool      `json:"end"`
+	Page       int       `json:"page"`
+	Limit      int       `json:"limit"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Comments   []Comment `json:"comments"`
+	End        bool      `json:"end"`
 }
 
 // APIProjectUpdate is the structure of the POST /api/project/{id}.
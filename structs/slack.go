@@ -0,0 +1,32 @@
+package structs
+
+// SlackMessage is the structure of a message sent to a slack incoming
+// webhook. Text is shown in notifications when blocks are present.
+type SlackMessage struct {
+	Text   string        `json:"text"`
+	Blocks []*SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a Block Kit layout block. Section blocks use Text and
+// Fields, context blocks use Elements.
+type SlackBlock struct {
+	Type     string               `json:"type"`
+	Text     *SlackText           `json:"text,omitempty"`
+	Fields   []*SlackText         `json:"fields,omitempty"`
+	Elements []*SlackBlockElement `json:"elements,omitempty"`
+}
+
+// SlackText is a Block Kit text object.
+type SlackText struct {
+	Type string `json:"type"` // mrkdwn or plain_text
+	Text string `json:"text"`
+}
+
+// SlackBlockElement is an element of a context block which is either text
+// or an image.
+type SlackBlockElement struct {
+	Type     string `json:"type"` // mrkdwn, plain_text or image
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	AltText  string `json:"alt_text,omitempty"`
+}
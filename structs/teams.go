@@ -0,0 +1,52 @@
+package structs
+
+// TeamsMessage is the structure of a message sent to a microsoft teams
+// incoming webhook.
+type TeamsMessage struct {
+	Type        string             `json:"type"` // Always message
+	Attachments []*TeamsAttachment `json:"attachments"`
+}
+
+// TeamsAttachment is an attachment of a teams message.
+type TeamsAttachment struct {
+	ContentType string        `json:"contentType"` // application/vnd.microsoft.card.adaptive
+	Content     *AdaptiveCard `json:"content"`
+}
+
+// AdaptiveCard is the structure of an Adaptive Card.
+type AdaptiveCard struct {
+	Schema  string                `json:"$schema"`
+	Type    string                `json:"type"` // Always AdaptiveCard
+	Version string                `json:"version"`
+	Body    []*AdaptiveCardItem   `json:"body"`
+	Actions []*AdaptiveCardAction `json:"actions,omitempty"`
+}
+
+// AdaptiveCardItem is an element of an Adaptive Card body. TextBlocks use
+// the text properties and FactSets use Facts.
+type AdaptiveCardItem struct {
+	Type string `json:"type"` // TextBlock or FactSet
+
+	Text     string `json:"text,omitempty"`
+	Size     string `json:"size,omitempty"`
+	Weight   string `json:"weight,omitempty"`
+	Color    string `json:"color,omitempty"`
+	FontType string `json:"fontType,omitempty"`
+	IsSubtle bool   `json:"isSubtle,omitempty"`
+	Wrap     bool   `json:"wrap,omitempty"`
+
+	Facts []*AdaptiveCardFact `json:"facts,omitempty"`
+}
+
+// AdaptiveCardFact is a name and value shown in a FactSet.
+type AdaptiveCardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// AdaptiveCardAction is a button shown at the bottom of an Adaptive Card.
+type AdaptiveCardAction struct {
+	Type  string `json:"type"` // Action.OpenUrl
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}